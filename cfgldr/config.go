@@ -1,6 +1,8 @@
 package cfgldr
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
@@ -15,19 +17,94 @@ type Database struct {
 }
 
 type S3 struct {
-	BucketName string `mapstructure:"bucket_name"`
-	Region     string `mapstructure:"region"`
+	BucketName      string `mapstructure:"bucket_name"`
+	Region          string `mapstructure:"region"`
+	Endpoint        string `mapstructure:"endpoint"`
+	UsePathStyle    bool   `mapstructure:"use_path_style"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	PublicBaseUrl   string `mapstructure:"public_base_url"`
+}
+
+type Storage struct {
+	Driver string `mapstructure:"driver"`
+	Local  Local  `mapstructure:"local"`
+}
+
+type Local struct {
+	RootDir    string `mapstructure:"root_dir"`
+	BaseUrl    string `mapstructure:"base_url"`
+	Serve      bool   `mapstructure:"serve"`
+	Port       int    `mapstructure:"port"`
+	SigningKey string `mapstructure:"signing_key"`
+}
+
+type Image struct {
+	MaxFileSize         int64          `mapstructure:"max_file_size"`
+	MaxWidth            int            `mapstructure:"max_width"`
+	MaxHeight           int            `mapstructure:"max_height"`
+	MaxPixels           int64          `mapstructure:"max_pixels"`
+	StripMetadata       bool           `mapstructure:"strip_metadata"`
+	UploadUrlExpiry     time.Duration  `mapstructure:"upload_url_expiry"`
+	DownloadUrlExpiry   time.Duration  `mapstructure:"download_url_expiry"`
+	VariantWidths       []int          `mapstructure:"variant_widths"`
+	SimilarityThreshold int            `mapstructure:"similarity_threshold"`
+	Compensation        Compensation   `mapstructure:"compensation"`
+	Reconciliation      Reconciliation `mapstructure:"reconciliation"`
+	Janitor             Janitor        `mapstructure:"janitor"`
+	Purge               Purge          `mapstructure:"purge"`
+}
+
+// Compensation configures how the objects of an upload that could not be stored are deleted, first right away
+// with retries and then by the compensation job, which also deletes the objects of the purged images.
+type Compensation struct {
+	Retries   int           `mapstructure:"retries"`
+	Backoff   time.Duration `mapstructure:"backoff"`
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batch_size"`
+}
+
+// Reconciliation configures the job that compares the bucket with the db. It only reports the differences
+// unless Apply is set, then it deletes the objects unknown to the db and flags the images whose objects are
+// missing. Objects and images newer than the grace period are left alone as their upload may be in progress.
+type Reconciliation struct {
+	Interval    time.Duration `mapstructure:"interval"`
+	GracePeriod time.Duration `mapstructure:"grace_period"`
+	Apply       bool          `mapstructure:"apply"`
+	BatchSize   int           `mapstructure:"batch_size"`
+}
+
+// Janitor configures the job that deletes the images that were left unassigned to a pet for longer than the
+// TTL, such as the uploads of an abandoned pet form, and the pending images whose upload was not confirmed
+// within the pending TTL, which cannot be shorter than the expiry of the upload url. A pending TTL of 0 keeps the
+// pending images.
+type Janitor struct {
+	TTL        time.Duration `mapstructure:"ttl"`
+	PendingTTL time.Duration `mapstructure:"pending_ttl"`
+	Interval   time.Duration `mapstructure:"interval"`
+	BatchSize  int           `mapstructure:"batch_size"`
+}
+
+// Purge configures the job that permanently deletes the images that were deleted longer than the retention
+// ago, until then they can be restored.
+type Purge struct {
+	Retention time.Duration `mapstructure:"retention"`
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batch_size"`
 }
 
 type App struct {
-	Port  int  `mapstructure:"port"`
-	Debug bool `mapstructure:"debug"`
+	Port        int  `mapstructure:"port"`
+	Debug       bool `mapstructure:"debug"`
+	MetricsPort int  `mapstructure:"metrics_port"`
 }
 
 type Config struct {
 	App      App      `mapstructure:"app"`
 	Database Database `mapstructure:"database"`
 	S3       S3       `mapstructure:"s3"`
+	Storage  Storage  `mapstructure:"storage"`
+	Image    Image    `mapstructure:"image"`
 }
 
 func LoadConfig() (config *Config, err error) {
@@ -37,6 +114,31 @@ func LoadConfig() (config *Config, err error) {
 
 	viper.AutomaticEnv()
 
+	viper.SetDefault("image.max_file_size", 50*1024*1024)
+	viper.SetDefault("image.max_width", 8000)
+	viper.SetDefault("image.max_height", 8000)
+	viper.SetDefault("image.max_pixels", 25_000_000)
+	viper.SetDefault("image.strip_metadata", true)
+	viper.SetDefault("image.upload_url_expiry", 15*time.Minute)
+	viper.SetDefault("image.download_url_expiry", 15*time.Minute)
+	viper.SetDefault("image.variant_widths", []int{160, 480, 1080})
+	viper.SetDefault("image.similarity_threshold", 10)
+	viper.SetDefault("image.compensation.retries", 3)
+	viper.SetDefault("image.compensation.backoff", 200*time.Millisecond)
+	viper.SetDefault("image.compensation.interval", 5*time.Minute)
+	viper.SetDefault("image.compensation.batch_size", 100)
+	viper.SetDefault("image.reconciliation.interval", 24*time.Hour)
+	viper.SetDefault("image.reconciliation.grace_period", 24*time.Hour)
+	viper.SetDefault("image.reconciliation.apply", false)
+	viper.SetDefault("image.reconciliation.batch_size", 500)
+	viper.SetDefault("image.janitor.ttl", 72*time.Hour)
+	viper.SetDefault("image.janitor.pending_ttl", time.Hour)
+	viper.SetDefault("image.janitor.interval", time.Hour)
+	viper.SetDefault("image.janitor.batch_size", 100)
+	viper.SetDefault("image.purge.retention", 30*24*time.Hour)
+	viper.SetDefault("image.purge.interval", time.Hour)
+	viper.SetDefault("image.purge.batch_size", 100)
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		return nil, errors.Wrap(err, "error occurs while reading the config")
@@ -47,5 +149,39 @@ func LoadConfig() (config *Config, err error) {
 		return nil, errors.Wrap(err, "error occurs while unmarshal the config")
 	}
 
+	err = config.validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "error occurs while validating the config")
+	}
+
 	return
 }
+
+// validate rejects the values the jobs cannot run with, an interval of 0 disables its job.
+func (c *Config) validate() error {
+	intervals := []struct {
+		key      string
+		interval time.Duration
+	}{
+		{key: "image.compensation.interval", interval: c.Image.Compensation.Interval},
+		{key: "image.reconciliation.interval", interval: c.Image.Reconciliation.Interval},
+		{key: "image.janitor.interval", interval: c.Image.Janitor.Interval},
+		{key: "image.purge.interval", interval: c.Image.Purge.Interval},
+	}
+	for _, i := range intervals {
+		if i.interval < 0 {
+			return errors.Errorf("%v must not be negative", i.key)
+		}
+	}
+
+	// a TTL of 0 would delete the images of the uploads that are in progress
+	janitor := c.Image.Janitor
+	if janitor.Interval > 0 && janitor.TTL <= 0 {
+		return errors.New("image.janitor.ttl must be positive")
+	}
+	if janitor.Interval > 0 && janitor.PendingTTL > 0 && janitor.PendingTTL < c.Image.UploadUrlExpiry {
+		return errors.New("image.janitor.pending_ttl must not be shorter than image.upload_url_expiry")
+	}
+
+	return nil
+}
@@ -3,42 +3,89 @@ package bucket
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"net/url"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/isd-sgcu/johnjud-file/cfgldr"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
+var ErrObjectNotFound = errors.New("Object not found")
+
+// ErrUploadIdle cancels an upload that did not make progress for the idle timeout.
+var ErrUploadIdle = errors.New("Upload was idle for too long")
+
+// uploadIdleTimeout is how long an upload may go without reading any data before it is cancelled, it also bounds
+// the upload of a part while the reader waits for it.
+const uploadIdleTimeout = 50 * time.Second
+
+// Object is an object read from a bucket, the caller must close Body.
+type Object struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+	ETag        string
+}
+
+// ObjectInfo is the metadata of an object in a bucket.
+type ObjectInfo struct {
+	Key          string
+	ContentType  string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
 type Client struct {
-	conf cfgldr.S3
-	s3   *s3.Client
+	conf        cfgldr.S3
+	s3          *s3.Client
+	idleTimeout time.Duration
 }
 
 func NewClient(conf cfgldr.S3, awsClient *s3.Client) *Client {
-	return &Client{conf: conf, s3: awsClient}
+	return &Client{conf: conf, s3: awsClient, idleTimeout: uploadIdleTimeout}
 }
 
 func (c *Client) Upload(file []byte, objectKey string) (string, string, error) {
-	ctx := context.Background()
-	ctx, cancel := context.WithTimeout(ctx, 50*time.Second)
-	defer cancel()
+	return c.UploadStream(context.Background(), bytes.NewReader(file), objectKey, "")
+}
+
+// UploadStream uploads the object while it is being read, so the whole file never has to be kept in memory.
+// The multipart uploader only buffers one part at a time. The upload ends with ctx or when it does not make
+// progress for the idle timeout, there is no deadline for the whole upload so a slow but steady client is not
+// cut off.
+func (c *Client) UploadStream(ctx context.Context, reader io.Reader, objectKey string, contentType string) (string, string, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	idle := newIdleReader(ctx, reader, c.idleTimeout, cancel)
+	defer idle.stop()
 
-	buffer := bytes.NewReader(file)
 	var partMiBs int64 = 10
 	uploader := manager.NewUploader(c.s3, func(u *manager.Uploader) {
 		u.PartSize = partMiBs * 1024 * 1024
 	})
 
-	uploadOutput, err := uploader.Upload(context.TODO(), &s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket: aws.String(c.conf.BucketName),
 		Key:    aws.String(objectKey),
-		Body:   buffer,
-	})
+		Body:   idle,
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
 
+	uploadOutput, err := uploader.Upload(ctx, input)
+	if context.Cause(ctx) != nil {
+		err = context.Cause(ctx)
+	}
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -49,7 +96,159 @@ func (c *Client) Upload(file []byte, objectKey string) (string, string, error) {
 		return "", "", errors.Wrap(err, "Error while uploading the object")
 	}
 
-	return uploadOutput.Location, *uploadOutput.Key, nil
+	imageUrl, err := c.ObjectUrl(*uploadOutput.Key)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Error while building the object url")
+	}
+
+	return imageUrl, *uploadOutput.Key, nil
+}
+
+// Copy copies the object to another key inside of the bucket, the content type is copied with it.
+func (c *Client) Copy(srcKey string, objectKey string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+
+	_, err := c.s3.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(c.conf.BucketName),
+		Key:        aws.String(objectKey),
+		CopySource: aws.String(c.conf.BucketName + "/" + url.PathEscape(srcKey)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return "", "", ErrObjectNotFound
+		}
+
+		log.Error().
+			Err(err).
+			Str("service", "file").
+			Str("module", "bucket client").
+			Msgf("Couldn't copy object %v to %v:%v.", srcKey, c.conf.BucketName, objectKey)
+
+		return "", "", errors.Wrap(err, "Error while copying the object")
+	}
+
+	imageUrl, err := c.ObjectUrl(objectKey)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Error while building the object url")
+	}
+
+	return imageUrl, objectKey, nil
+}
+
+func (c *Client) Get(objectKey string) (*Object, error) {
+	// the timeout also covers reading the body, so it is cancelled when the body is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+
+	output, err := c.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.conf.BucketName),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		cancel()
+
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrObjectNotFound
+		}
+
+		log.Error().
+			Err(err).
+			Str("service", "file").
+			Str("module", "bucket client").
+			Msgf("Couldn't get object from %v:%v.", c.conf.BucketName, objectKey)
+
+		return nil, errors.Wrap(err, "Error while getting the object")
+	}
+
+	return &Object{
+		Body:        &cancelReadCloser{ReadCloser: output.Body, cancel: cancel},
+		ContentType: aws.ToString(output.ContentType),
+		Size:        aws.ToInt64(output.ContentLength),
+		ETag:        aws.ToString(output.ETag),
+	}, nil
+}
+
+func (c *Client) Head(objectKey string) (*ObjectInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+
+	output, err := c.s3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.conf.BucketName),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, ErrObjectNotFound
+		}
+
+		log.Error().
+			Err(err).
+			Str("service", "file").
+			Str("module", "bucket client").
+			Msgf("Couldn't head object from %v:%v.", c.conf.BucketName, objectKey)
+
+		return nil, errors.Wrap(err, "Error while getting the object metadata")
+	}
+
+	return &ObjectInfo{
+		Key:          objectKey,
+		ContentType:  aws.ToString(output.ContentType),
+		Size:         aws.ToInt64(output.ContentLength),
+		ETag:         aws.ToString(output.ETag),
+		LastModified: aws.ToTime(output.LastModified),
+	}, nil
+}
+
+// PresignUpload returns a url that allows a client to put the object directly into the bucket until it expires.
+func (c *Client) PresignUpload(objectKey string, contentType string, expiry time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(c.conf.BucketName),
+		Key:    aws.String(objectKey),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	req, err := s3.NewPresignClient(c.s3).PresignPutObject(ctx, input, s3.WithPresignExpires(expiry))
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("service", "file").
+			Str("module", "bucket client").
+			Msgf("Couldn't presign upload to %v:%v.", c.conf.BucketName, objectKey)
+
+		return "", errors.Wrap(err, "Error while presigning the upload")
+	}
+
+	return req.URL, nil
+}
+
+// PresignDownload returns a url that allows a client to get a private object until it expires.
+func (c *Client) PresignDownload(objectKey string, expiry time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+
+	req, err := s3.NewPresignClient(c.s3).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.conf.BucketName),
+		Key:    aws.String(objectKey),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("service", "file").
+			Str("module", "bucket client").
+			Msgf("Couldn't presign download from %v:%v.", c.conf.BucketName, objectKey)
+
+		return "", errors.Wrap(err, "Error while presigning the download")
+	}
+
+	return req.URL, nil
 }
 
 func (c *Client) Delete(objectKey string) error {
@@ -62,7 +261,7 @@ func (c *Client) Delete(objectKey string) error {
 		Key:    aws.String(objectKey),
 	}
 
-	_, err := c.s3.DeleteObject(context.TODO(), input)
+	_, err := c.s3.DeleteObject(ctx, input)
 
 	if err != nil {
 		log.Error().
@@ -76,3 +275,128 @@ func (c *Client) Delete(objectKey string) error {
 
 	return nil
 }
+
+// List calls fn with every object in the bucket, the objects are listed one page at a time so the bucket is
+// never held in memory. The content type is not part of the listing and is left empty.
+func (c *Client) List(fn func(*ObjectInfo) error) error {
+	paginator := s3.NewListObjectsV2Paginator(c.s3, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.conf.BucketName),
+	})
+
+	for paginator.HasMorePages() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+		output, err := paginator.NextPage(ctx)
+		cancel()
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("service", "file").
+				Str("module", "bucket client").
+				Msgf("Couldn't list objects from bucket %v.", c.conf.BucketName)
+
+			return errors.Wrap(err, "Error while listing the objects")
+		}
+
+		for _, object := range output.Contents {
+			err = fn(&ObjectInfo{
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				ETag:         aws.ToString(object.ETag),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ObjectUrl builds the public url of an object. The configured base url takes precedence because
+// S3-compatible storages are often reached through an internal endpoint.
+func (c *Client) ObjectUrl(objectKey string) (string, error) {
+	if c.conf.PublicBaseUrl != "" {
+		return url.JoinPath(c.conf.PublicBaseUrl, objectKey)
+	}
+
+	if c.conf.Endpoint == "" {
+		return url.JoinPath(fmt.Sprintf("https://%v.s3.%v.amazonaws.com", c.conf.BucketName, c.conf.Region), objectKey)
+	}
+
+	if c.conf.UsePathStyle {
+		return url.JoinPath(c.conf.Endpoint, c.conf.BucketName, objectKey)
+	}
+
+	endpoint, err := url.Parse(c.conf.Endpoint)
+	if err != nil {
+		return "", err
+	}
+	endpoint.Host = c.conf.BucketName + "." + endpoint.Host
+
+	return endpoint.JoinPath(objectKey).String(), nil
+}
+
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReadCloser) Close() error {
+	defer r.cancel()
+
+	return r.ReadCloser.Close()
+}
+
+// idleReader cancels the upload when the reader has not returned any data for the timeout, which also covers a
+// part that takes too long to upload while the reader is not read. A read that blocks is ended by the context of
+// the caller, such as the one of a stream that is closed.
+type idleReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+func newIdleReader(ctx context.Context, reader io.Reader, timeout time.Duration, cancel context.CancelCauseFunc) *idleReader {
+	return &idleReader{
+		ctx:     ctx,
+		reader:  reader,
+		timeout: timeout,
+		timer:   time.AfterFunc(timeout, func() { cancel(ErrUploadIdle) }),
+	}
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	if r.ctx.Err() != nil {
+		return 0, context.Cause(r.ctx)
+	}
+
+	n, err := r.reader.Read(p)
+	if r.ctx.Err() != nil {
+		return 0, context.Cause(r.ctx)
+	}
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+
+	return n, err
+}
+
+func (r *idleReader) stop() {
+	r.timer.Stop()
+}
+
+// contextReader stops reading once the context is done.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if r.ctx.Err() != nil {
+		return 0, context.Cause(r.ctx)
+	}
+
+	return r.reader.Read(p)
+}
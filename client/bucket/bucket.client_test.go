@@ -0,0 +1,126 @@
+package bucket
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectUrlFromPublicBaseUrl(t *testing.T) {
+	client := NewClient(cfgldr.S3{
+		BucketName:    "johnjud",
+		Endpoint:      "http://minio:9000",
+		UsePathStyle:  true,
+		PublicBaseUrl: "http://localhost:9000/johnjud",
+	}, nil)
+
+	actual, err := client.ObjectUrl("pet image.png_random")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:9000/johnjud/pet%20image.png_random", actual)
+}
+
+func TestObjectUrlFromRegion(t *testing.T) {
+	client := NewClient(cfgldr.S3{BucketName: "johnjud", Region: "ap-southeast-1"}, nil)
+
+	actual, err := client.ObjectUrl("pet.png_random")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://johnjud.s3.ap-southeast-1.amazonaws.com/pet.png_random", actual)
+}
+
+func TestObjectUrlFromPathStyleEndpoint(t *testing.T) {
+	client := NewClient(cfgldr.S3{BucketName: "johnjud", Endpoint: "http://minio:9000", UsePathStyle: true}, nil)
+
+	actual, err := client.ObjectUrl("pet.png_random")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://minio:9000/johnjud/pet.png_random", actual)
+}
+
+func TestObjectUrlFromVirtualHostedEndpoint(t *testing.T) {
+	client := NewClient(cfgldr.S3{BucketName: "johnjud", Endpoint: "https://sgp1.digitaloceanspaces.com"}, nil)
+
+	actual, err := client.ObjectUrl("pet.png_random")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://johnjud.sgp1.digitaloceanspaces.com/pet.png_random", actual)
+}
+
+// slowReader returns its data a byte at a time with a delay before each read.
+type slowReader struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+
+	time.Sleep(r.delay)
+	n := copy(p[:1], r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func newTestS3Client(t *testing.T, idleTimeout time.Duration) (*Client, *[]byte) {
+	var stored []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stored, _ = io.ReadAll(r.Body)
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	t.Cleanup(server.Close)
+
+	awsClient := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	})
+	client := NewClient(cfgldr.S3{BucketName: "johnjud", Endpoint: server.URL, UsePathStyle: true}, awsClient)
+	client.idleTimeout = idleTimeout
+
+	return client, &stored
+}
+
+func TestUploadStreamSlowReader(t *testing.T) {
+	client, stored := newTestS3Client(t, 100*time.Millisecond)
+
+	// the upload takes longer than the idle timeout, but it never waits that long for the next byte
+	_, key, err := client.UploadStream(context.Background(), &slowReader{data: []byte("0123456789"), delay: 30 * time.Millisecond}, "slow", "text/plain")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "slow", key)
+	assert.Equal(t, []byte("0123456789"), *stored)
+}
+
+func TestUploadStreamIdleReader(t *testing.T) {
+	client, stored := newTestS3Client(t, 50*time.Millisecond)
+
+	_, _, err := client.UploadStream(context.Background(), &slowReader{data: []byte("0123"), delay: 100 * time.Millisecond}, "idle", "text/plain")
+
+	assert.ErrorIs(t, err, ErrUploadIdle)
+	assert.Nil(t, *stored)
+}
+
+func TestUploadStreamCancelled(t *testing.T) {
+	client, stored := newTestS3Client(t, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := client.UploadStream(ctx, &slowReader{data: []byte("0123")}, "cancelled", "text/plain")
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, *stored)
+}
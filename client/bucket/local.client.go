@@ -0,0 +1,359 @@
+package bucket
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// LocalClient stores objects as files under a root directory, for development and integration tests
+// that should not depend on a real bucket.
+type LocalClient struct {
+	conf cfgldr.Local
+}
+
+func NewLocalClient(conf cfgldr.Local) *LocalClient {
+	return &LocalClient{conf: conf}
+}
+
+func (c *LocalClient) Upload(file []byte, objectKey string) (string, string, error) {
+	return c.UploadStream(context.Background(), bytes.NewReader(file), objectKey, "")
+}
+
+// UploadStream writes the object to disk while it is being read, nothing is written when ctx ends first. The
+// content type is not kept because the file handler detects it from the file itself.
+func (c *LocalClient) UploadStream(ctx context.Context, reader io.Reader, objectKey string, _ string) (string, string, error) {
+	path, err := c.path(objectKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = writeFile(path, &contextReader{ctx: ctx, reader: reader})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("service", "file").
+			Str("module", "local client").
+			Msgf("Couldn't write object to %v:%v.", c.conf.RootDir, objectKey)
+
+		return "", "", errors.Wrap(err, "Error while uploading the object")
+	}
+
+	imageUrl, err := c.ObjectUrl(objectKey)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Error while building the object url")
+	}
+
+	return imageUrl, objectKey, nil
+}
+
+// Copy writes a copy of the object file under the other key.
+func (c *LocalClient) Copy(srcKey string, objectKey string) (string, string, error) {
+	file, _, head, err := c.open(srcKey)
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	return c.UploadStream(context.Background(), io.MultiReader(bytes.NewReader(head), file), objectKey, "")
+}
+
+func (c *LocalClient) Get(objectKey string) (*Object, error) {
+	file, info, head, err := c.open(objectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Object{
+		Body:        readCloser{Reader: io.MultiReader(bytes.NewReader(head), file), Closer: file},
+		ContentType: http.DetectContentType(head),
+		Size:        info.Size(),
+		ETag:        localETag(info),
+	}, nil
+}
+
+func (c *LocalClient) Head(objectKey string) (*ObjectInfo, error) {
+	file, info, head, err := c.open(objectKey)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+
+	return &ObjectInfo{
+		Key:          objectKey,
+		ContentType:  http.DetectContentType(head),
+		Size:         info.Size(),
+		ETag:         localETag(info),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+// PresignUpload returns a url of the file handler that accepts a PUT of the object until it expires.
+func (c *LocalClient) PresignUpload(objectKey string, _ string, expiry time.Duration) (string, error) {
+	return c.presign(http.MethodPut, objectKey, expiry)
+}
+
+// PresignDownload returns a signed url of the object, the file handler serves every object
+// so the signature is only checked for uploads.
+func (c *LocalClient) PresignDownload(objectKey string, expiry time.Duration) (string, error) {
+	return c.presign(http.MethodGet, objectKey, expiry)
+}
+
+func (c *LocalClient) ObjectUrl(objectKey string) (string, error) {
+	return url.JoinPath(c.conf.BaseUrl, objectKey)
+}
+
+func (c *LocalClient) Delete(objectKey string) error {
+	path, err := c.path(objectKey)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		log.Error().
+			Err(err).
+			Str("service", "file").
+			Str("module", "local client").
+			Msgf("Couldn't delete object from %v:%v.", c.conf.RootDir, objectKey)
+
+		return errors.Wrap(err, "Error while deleting the object")
+	}
+
+	return nil
+}
+
+// List calls fn with every object under the root directory, the temporary files of uploads in progress are
+// skipped.
+func (c *LocalClient) List(fn func(*ObjectInfo) error) error {
+	root, err := filepath.Abs(c.conf.RootDir)
+	if err != nil {
+		return errors.Wrap(err, "Error while resolving the root directory")
+	}
+
+	err = filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := c.Head(filepath.ToSlash(rel))
+		if err == ErrObjectNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return fn(info)
+	})
+	if err != nil {
+		return errors.Wrap(err, "Error while listing the objects")
+	}
+
+	return nil
+}
+
+// path resolves the object key to a file under the root directory and rejects keys that would escape it.
+func (c *LocalClient) path(objectKey string) (string, error) {
+	root, err := filepath.Abs(c.conf.RootDir)
+	if err != nil {
+		return "", errors.Wrap(err, "Error while resolving the root directory")
+	}
+
+	path := filepath.Join(root, filepath.FromSlash(objectKey))
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("Invalid object key %q", objectKey)
+	}
+
+	return path, nil
+}
+
+// open opens the object file and reads the first 512 bytes of it to detect the content type.
+func (c *LocalClient) open(objectKey string) (*os.File, os.FileInfo, []byte, error) {
+	path, err := c.path(objectKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "Error while getting the object")
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, nil, errors.Wrap(err, "Error while getting the object")
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, nil, ErrObjectNotFound
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		file.Close()
+		return nil, nil, nil, errors.Wrap(err, "Error while getting the object")
+	}
+
+	return file, info, head[:n], nil
+}
+
+func (c *LocalClient) presign(method string, objectKey string, expiry time.Duration) (string, error) {
+	if c.conf.SigningKey == "" {
+		return "", errors.New("Signing key of the local storage is not configured")
+	}
+
+	objectUrl, err := c.ObjectUrl(objectKey)
+	if err != nil {
+		return "", errors.Wrap(err, "Error while building the object url")
+	}
+
+	presignedUrl, err := url.Parse(objectUrl)
+	if err != nil {
+		return "", errors.Wrap(err, "Error while building the object url")
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	query := presignedUrl.Query()
+	query.Set("expires", expires)
+	query.Set("signature", localSignature(c.conf.SigningKey, method, objectKey, expires))
+	presignedUrl.RawQuery = query.Encode()
+
+	return presignedUrl.String(), nil
+}
+
+func localSignature(signingKey string, method string, objectKey string, expires string) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(method + "\n" + objectKey + "\n" + expires))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func localETag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+// writeFile writes to a temporary file first so the file handler never serves a partially written object.
+func writeFile(path string, reader io.Reader) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp.Name(), 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// NewLocalFileHandler serves the stored objects at the path of the configured base url. The presigned uploads
+// are rejected when they are larger than maxSize, 0 does not limit them.
+func NewLocalFileHandler(conf cfgldr.Local, maxSize int64) http.Handler {
+	prefix := ""
+	if baseUrl, err := url.Parse(conf.BaseUrl); err == nil {
+		prefix = strings.TrimSuffix(baseUrl.Path, "/")
+	}
+
+	client := NewLocalClient(conf)
+	fileServer := http.FileServer(http.Dir(conf.RootDir))
+
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// directory listings and temporary upload files are never exposed
+		if strings.HasSuffix(r.URL.Path, "/") || strings.HasPrefix(filepath.Base(r.URL.Path), ".upload-") {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			client.handlePresignedUpload(w, r, maxSize)
+			return
+		}
+
+		fileServer.ServeHTTP(w, r)
+	}))
+}
+
+func (c *LocalClient) handlePresignedUpload(w http.ResponseWriter, r *http.Request, maxSize int64) {
+	objectKey := strings.TrimPrefix(r.URL.Path, "/")
+	expires := r.URL.Query().Get("expires")
+	signature := r.URL.Query().Get("signature")
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || c.conf.SigningKey == "" || time.Now().Unix() > expiresAt ||
+		!hmac.Equal([]byte(signature), []byte(localSignature(c.conf.SigningKey, http.MethodPut, objectKey, expires))) {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	body := r.Body
+	if maxSize > 0 {
+		body = http.MaxBytesReader(w, r.Body, maxSize)
+	}
+
+	// the temporary file is removed when the body is too large, so no part of it is stored
+	_, _, err = c.UploadStream(r.Context(), body, objectKey, r.Header.Get("Content-Type"))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "object is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
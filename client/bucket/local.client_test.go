@@ -0,0 +1,221 @@
+package bucket
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocalClientTest struct {
+	suite.Suite
+	conf      cfgldr.Local
+	file      []byte
+	objectKey string
+}
+
+func TestLocalClient(t *testing.T) {
+	suite.Run(t, new(LocalClientTest))
+}
+
+func (t *LocalClientTest) SetupTest() {
+	t.conf = cfgldr.Local{
+		RootDir:    t.T().TempDir(),
+		BaseUrl:    "http://localhost:3005/files",
+		SigningKey: "secret",
+	}
+	t.file = []byte("test")
+	t.objectKey = "pet image.png_random"
+}
+
+func (t *LocalClientTest) TestUploadSuccess() {
+	client := NewLocalClient(t.conf)
+
+	imageUrl, objectKey, err := client.Upload(t.file, t.objectKey)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "http://localhost:3005/files/pet%20image.png_random", imageUrl)
+	assert.Equal(t.T(), t.objectKey, objectKey)
+
+	actual, err := os.ReadFile(filepath.Join(t.conf.RootDir, t.objectKey))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), t.file, actual)
+}
+
+func (t *LocalClientTest) TestUploadInvalidObjectKey() {
+	client := NewLocalClient(t.conf)
+
+	_, _, err := client.Upload(t.file, "../outside")
+
+	assert.NotNil(t.T(), err)
+	_, statErr := os.Stat(filepath.Join(filepath.Dir(t.conf.RootDir), "outside"))
+	assert.True(t.T(), os.IsNotExist(statErr))
+}
+
+func (t *LocalClientTest) TestGetSuccess() {
+	client := NewLocalClient(t.conf)
+	_, _, err := client.Upload(t.file, t.objectKey)
+	assert.Nil(t.T(), err)
+
+	object, err := client.Get(t.objectKey)
+	assert.Nil(t.T(), err)
+	defer object.Body.Close()
+
+	body, err := io.ReadAll(object.Body)
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), t.file, body)
+	assert.Equal(t.T(), int64(len(t.file)), object.Size)
+	assert.Equal(t.T(), "text/plain; charset=utf-8", object.ContentType)
+	assert.NotEmpty(t.T(), object.ETag)
+}
+
+func (t *LocalClientTest) TestGetNotFound() {
+	client := NewLocalClient(t.conf)
+
+	object, err := client.Get(t.objectKey)
+
+	assert.Nil(t.T(), object)
+	assert.Equal(t.T(), ErrObjectNotFound, err)
+}
+
+func (t *LocalClientTest) TestCopySuccess() {
+	client := NewLocalClient(t.conf)
+	_, _, err := client.Upload(t.file, t.objectKey)
+	assert.Nil(t.T(), err)
+
+	imageUrl, objectKey, err := client.Copy(t.objectKey, "copy")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "copy", objectKey)
+	assert.Equal(t.T(), t.conf.BaseUrl+"/copy", imageUrl)
+	copied, err := os.ReadFile(filepath.Join(t.conf.RootDir, "copy"))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), t.file, copied)
+	_, err = os.Stat(filepath.Join(t.conf.RootDir, t.objectKey))
+	assert.Nil(t.T(), err)
+}
+
+func (t *LocalClientTest) TestCopyNotFound() {
+	client := NewLocalClient(t.conf)
+
+	_, _, err := client.Copy(t.objectKey, "copy")
+
+	assert.Equal(t.T(), ErrObjectNotFound, err)
+}
+
+func (t *LocalClientTest) TestDeleteSuccess() {
+	client := NewLocalClient(t.conf)
+	_, _, err := client.Upload(t.file, t.objectKey)
+	assert.Nil(t.T(), err)
+
+	err = client.Delete(t.objectKey)
+
+	assert.Nil(t.T(), err)
+	_, statErr := os.Stat(filepath.Join(t.conf.RootDir, t.objectKey))
+	assert.True(t.T(), os.IsNotExist(statErr))
+}
+
+func (t *LocalClientTest) TestDeleteNotExist() {
+	client := NewLocalClient(t.conf)
+
+	err := client.Delete(t.objectKey)
+
+	assert.Nil(t.T(), err)
+}
+
+func (t *LocalClientTest) TestListSuccess() {
+	client := NewLocalClient(t.conf)
+	for _, objectKey := range []string{t.objectKey, "variants/" + t.objectKey + "_160w"} {
+		_, _, err := client.Upload(t.file, objectKey)
+		assert.Nil(t.T(), err)
+	}
+	err := os.WriteFile(filepath.Join(t.conf.RootDir, ".upload-123"), t.file, 0o644)
+	assert.Nil(t.T(), err)
+
+	var keys []string
+	err = client.List(func(info *ObjectInfo) error {
+		keys = append(keys, info.Key)
+		assert.Equal(t.T(), int64(len(t.file)), info.Size)
+		assert.False(t.T(), info.LastModified.IsZero())
+		return nil
+	})
+
+	assert.Nil(t.T(), err)
+	assert.ElementsMatch(t.T(), []string{t.objectKey, "variants/" + t.objectKey + "_160w"}, keys)
+}
+
+func (t *LocalClientTest) TestListEmptyRoot() {
+	t.conf.RootDir = filepath.Join(t.conf.RootDir, "missing")
+	client := NewLocalClient(t.conf)
+
+	err := client.List(func(info *ObjectInfo) error {
+		t.T().Fatalf("unexpected object %v", info.Key)
+		return nil
+	})
+
+	assert.Nil(t.T(), err)
+}
+
+func (t *LocalClientTest) TestFileHandler() {
+	client := NewLocalClient(t.conf)
+	imageUrl, _, err := client.Upload(t.file, t.objectKey)
+	assert.Nil(t.T(), err)
+
+	handler := NewLocalFileHandler(t.conf, 0)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, imageUrl, nil))
+	body, _ := io.ReadAll(rec.Body)
+	assert.Equal(t.T(), http.StatusOK, rec.Code)
+	assert.Equal(t.T(), t.file, body)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://localhost:3005/files/", nil))
+	assert.Equal(t.T(), http.StatusNotFound, rec.Code)
+}
+
+func (t *LocalClientTest) TestPresignedUpload() {
+	client := NewLocalClient(t.conf)
+	uploadUrl, err := client.PresignUpload(t.objectKey, "text/plain", time.Minute)
+	assert.Nil(t.T(), err)
+
+	handler := NewLocalFileHandler(t.conf, 4)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, uploadUrl, strings.NewReader("test")))
+	assert.Equal(t.T(), http.StatusOK, rec.Code)
+
+	info, err := client.Head(t.objectKey)
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), int64(len(t.file)), info.Size)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, strings.Replace(uploadUrl, "signature=", "signature=0", 1), strings.NewReader("test")))
+	assert.Equal(t.T(), http.StatusForbidden, rec.Code)
+}
+
+func (t *LocalClientTest) TestPresignedUploadTooLarge() {
+	client := NewLocalClient(t.conf)
+	uploadUrl, err := client.PresignUpload(t.objectKey, "text/plain", time.Minute)
+	assert.Nil(t.T(), err)
+
+	handler := NewLocalFileHandler(t.conf, 3)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, uploadUrl, strings.NewReader("test")))
+	assert.Equal(t.T(), http.StatusRequestEntityTooLarge, rec.Code)
+
+	_, err = client.Head(t.objectKey)
+	assert.Equal(t.T(), ErrObjectNotFound, err)
+	entries, err := os.ReadDir(t.conf.RootDir)
+	assert.Nil(t.T(), err)
+	assert.Empty(t.T(), entries)
+}
@@ -0,0 +1,271 @@
+package bucket
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const memoryBaseUrl = "memory://objects"
+
+type MemoryObject struct {
+	Data        []byte
+	ContentType string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// MemoryClient keeps objects in memory. It is safe for concurrent use and is meant for tests and
+// ephemeral environments, where failures and latency can be injected to exercise error paths.
+type MemoryClient struct {
+	mu        sync.RWMutex
+	objects   map[string]*MemoryObject
+	latency   time.Duration
+	uploadErr error
+	getErr    error
+	deleteErr error
+}
+
+func NewMemoryClient() *MemoryClient {
+	return &MemoryClient{objects: map[string]*MemoryObject{}}
+}
+
+func (c *MemoryClient) Upload(file []byte, objectKey string) (string, string, error) {
+	return c.UploadStream(context.Background(), bytes.NewReader(file), objectKey, "")
+}
+
+// UploadStream stores the object with the given content type, or with a detected one when it is empty.
+func (c *MemoryClient) UploadStream(ctx context.Context, reader io.Reader, objectKey string, contentType string) (string, string, error) {
+	if err := c.wait(func() error { return c.uploadErr }); err != nil {
+		return "", "", errors.Wrap(err, "Error while uploading the object")
+	}
+
+	data, err := io.ReadAll(&contextReader{ctx: ctx, reader: reader})
+	if err != nil {
+		return "", "", errors.Wrap(err, "Error while uploading the object")
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	imageUrl, err := c.ObjectUrl(objectKey)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Error while building the object url")
+	}
+
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	object, ok := c.objects[objectKey]
+	if !ok {
+		object = &MemoryObject{CreatedAt: now}
+		c.objects[objectKey] = object
+	}
+	object.Data = data
+	object.ContentType = contentType
+	object.UpdatedAt = now
+
+	return imageUrl, objectKey, nil
+}
+
+func (c *MemoryClient) Copy(srcKey string, objectKey string) (string, string, error) {
+	object, ok := c.Object(srcKey)
+	if !ok {
+		return "", "", ErrObjectNotFound
+	}
+
+	return c.UploadStream(context.Background(), bytes.NewReader(object.Data), objectKey, object.ContentType)
+}
+
+func (c *MemoryClient) Get(objectKey string) (*Object, error) {
+	if err := c.wait(func() error { return c.getErr }); err != nil {
+		return nil, errors.Wrap(err, "Error while getting the object")
+	}
+
+	object, ok := c.Object(objectKey)
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+
+	return &Object{
+		Body:        io.NopCloser(bytes.NewReader(object.Data)),
+		ContentType: object.ContentType,
+		Size:        int64(len(object.Data)),
+		ETag:        fmt.Sprintf(`"%x"`, md5.Sum(object.Data)),
+	}, nil
+}
+
+func (c *MemoryClient) Head(objectKey string) (*ObjectInfo, error) {
+	if err := c.wait(func() error { return c.getErr }); err != nil {
+		return nil, errors.Wrap(err, "Error while getting the object metadata")
+	}
+
+	object, ok := c.Object(objectKey)
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+
+	return &ObjectInfo{
+		Key:          objectKey,
+		ContentType:  object.ContentType,
+		Size:         int64(len(object.Data)),
+		ETag:         fmt.Sprintf(`"%x"`, md5.Sum(object.Data)),
+		LastModified: object.UpdatedAt,
+	}, nil
+}
+
+// PresignUpload returns a url that only identifies the upload, objects are put with UploadStream.
+func (c *MemoryClient) PresignUpload(objectKey string, _ string, expiry time.Duration) (string, error) {
+	return c.presign(http.MethodPut, objectKey, expiry)
+}
+
+func (c *MemoryClient) PresignDownload(objectKey string, expiry time.Duration) (string, error) {
+	return c.presign(http.MethodGet, objectKey, expiry)
+}
+
+func (c *MemoryClient) ObjectUrl(objectKey string) (string, error) {
+	return url.JoinPath(memoryBaseUrl, objectKey)
+}
+
+func (c *MemoryClient) Delete(objectKey string) error {
+	if err := c.wait(func() error { return c.deleteErr }); err != nil {
+		return errors.Wrap(err, "Error while deleting the object")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.objects, objectKey)
+
+	return nil
+}
+
+// List calls fn with every stored object in lexical order of the keys.
+func (c *MemoryClient) List(fn func(*ObjectInfo) error) error {
+	if err := c.wait(func() error { return c.getErr }); err != nil {
+		return errors.Wrap(err, "Error while listing the objects")
+	}
+
+	for _, key := range c.Keys() {
+		info, err := c.Head(key)
+		if err == ErrObjectNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		err = fn(info)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Object returns a copy of the stored object.
+func (c *MemoryClient) Object(objectKey string) (MemoryObject, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	object, ok := c.objects[objectKey]
+	if !ok {
+		return MemoryObject{}, false
+	}
+
+	result := *object
+	result.Data = make([]byte, len(object.Data))
+	copy(result.Data, object.Data)
+
+	return result, true
+}
+
+// Keys returns the keys of all stored objects in lexical order.
+func (c *MemoryClient) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.objects))
+	for key := range c.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// SetLatency delays every following operation by d.
+func (c *MemoryClient) SetLatency(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.latency = d
+}
+
+// SetUploadError makes every following upload fail with err until it is reset with nil.
+func (c *MemoryClient) SetUploadError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.uploadErr = err
+}
+
+// SetGetError makes every following get fail with err until it is reset with nil.
+func (c *MemoryClient) SetGetError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.getErr = err
+}
+
+// SetModTime changes the time the object was last modified, to make it look older than it is.
+func (c *MemoryClient) SetModTime(objectKey string, modTime time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if object, ok := c.objects[objectKey]; ok {
+		object.UpdatedAt = modTime
+	}
+}
+
+// SetDeleteError makes every following delete fail with err until it is reset with nil.
+func (c *MemoryClient) SetDeleteError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.deleteErr = err
+}
+
+func (c *MemoryClient) presign(method string, objectKey string, expiry time.Duration) (string, error) {
+	objectUrl, err := c.ObjectUrl(objectKey)
+	if err != nil {
+		return "", errors.Wrap(err, "Error while building the object url")
+	}
+
+	return fmt.Sprintf("%v?method=%v&expires=%v", objectUrl, method, time.Now().Add(expiry).Unix()), nil
+}
+
+func (c *MemoryClient) wait(injectedErr func() error) error {
+	c.mu.RLock()
+	latency := c.latency
+	err := injectedErr()
+	c.mu.RUnlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	return err
+}
@@ -2,18 +2,24 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/constant"
 	"github.com/isd-sgcu/johnjud-file/database"
+	"github.com/isd-sgcu/johnjud-file/internal/job"
 	imageRepo "github.com/isd-sgcu/johnjud-file/internal/repository/image"
 	imageSvc "github.com/isd-sgcu/johnjud-file/internal/service/image"
 	"github.com/isd-sgcu/johnjud-file/internal/utils"
@@ -99,13 +105,43 @@ func main() {
 			Msg("Failed to init postgres connection")
 	}
 
-	sdkConfig, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
+	var bucketClient bucket.Client
+	switch conf.Storage.Driver {
+	case constant.S3StorageDriver, "":
+		var sdkOptions []func(*config.LoadOptions) error
+		if conf.S3.Region != "" {
+			sdkOptions = append(sdkOptions, config.WithRegion(conf.S3.Region))
+		}
+		if conf.S3.AccessKeyID != "" {
+			sdkOptions = append(sdkOptions, config.WithCredentialsProvider(
+				credentials.NewStaticCredentialsProvider(conf.S3.AccessKeyID, conf.S3.SecretAccessKey, ""),
+			))
+		}
+
+		sdkConfig, err := config.LoadDefaultConfig(context.TODO(), sdkOptions...)
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Str("service", "file").
+				Msg("Failed to load AWS SDK config")
+			return
+		}
+
+		awsClient := s3.NewFromConfig(sdkConfig, func(o *s3.Options) {
+			if conf.S3.Endpoint != "" {
+				o.BaseEndpoint = aws.String(conf.S3.Endpoint)
+			}
+			o.UsePathStyle = conf.S3.UsePathStyle
+		})
+		bucketClient = bucket.NewClient(conf.S3, awsClient)
+	case constant.LocalStorageDriver:
+		bucketClient = bucket.NewLocalClient(conf.Storage.Local)
+	case constant.MemoryStorageDriver:
+		bucketClient = bucket.NewMemoryClient()
+	default:
 		log.Fatal().
-			Err(err).
 			Str("service", "file").
-			Msg("Failed to load AWS SDK config")
-		return
+			Msgf("Unknown storage driver %q", conf.Storage.Driver)
 	}
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%v", conf.App.Port))
@@ -118,13 +154,25 @@ func main() {
 
 	grpcServer := grpc.NewServer()
 
-	awsClient := s3.NewFromConfig(sdkConfig)
-	bucketClient := bucket.NewClient(conf.S3, awsClient)
-
 	randomUtils := utils.NewRandomUtil()
+	imageUtils := utils.NewImageUtil()
 	imageRepository := imageRepo.NewRepository(db)
 
-	imageService := imageSvc.NewService(bucketClient, imageRepository, randomUtils)
+	imageService := imageSvc.NewService(bucketClient, imageRepository, randomUtils, imageUtils, conf.Image)
+
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	runJob := func(name string, interval time.Duration, j job.Job) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			job.Run(jobCtx, name, interval, j)
+		}()
+	}
+	runJob("compensation", conf.Image.Compensation.Interval, job.NewCompensationJob(bucketClient, imageRepository, conf.Image.Compensation))
+	runJob("reconciliation", conf.Image.Reconciliation.Interval, job.Exclusive("reconciliation", database.NewAdvisoryLock(db, database.ReconciliationLockKey), job.NewReconciliationJob(bucketClient, imageRepository, conf.Image.Reconciliation)))
+	runJob("janitor", conf.Image.Janitor.Interval, job.Exclusive("janitor", database.NewAdvisoryLock(db, database.JanitorLockKey), job.NewJanitorJob(imageRepository, conf.Image.Janitor)))
+	runJob("purge", conf.Image.Purge.Interval, job.NewPurgeJob(imageRepository, conf.Image.Purge))
 
 	grpc_health_v1.RegisterHealthServer(grpcServer, health.NewServer())
 	imagePb.RegisterImageServiceServer(grpcServer, imageService)
@@ -143,11 +191,16 @@ func main() {
 		}
 	}()
 
-	wait := gracefulShutdown(context.Background(), 2*time.Second, map[string]operation{
+	ops := map[string]operation{
 		"server": func(ctx context.Context) error {
 			grpcServer.GracefulStop()
 			return nil
 		},
+		"jobs": func(ctx context.Context) error {
+			stopJobs()
+			jobs.Wait()
+			return nil
+		},
 		"database": func(ctx context.Context) error {
 			sqlDB, err := db.DB()
 			if err != nil {
@@ -155,7 +208,59 @@ func main() {
 			}
 			return sqlDB.Close()
 		},
-	})
+	}
+
+	if conf.Storage.Driver == constant.LocalStorageDriver && conf.Storage.Local.Serve {
+		fileServer := &http.Server{
+			Addr:    fmt.Sprintf(":%v", conf.Storage.Local.Port),
+			Handler: bucket.NewLocalFileHandler(conf.Storage.Local, conf.Image.MaxFileSize),
+		}
+
+		go func() {
+			log.Info().
+				Str("service", "file").
+				Msgf("Local file server starting at port %v", conf.Storage.Local.Port)
+
+			if err := fileServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal().
+					Err(err).
+					Str("service", "file").
+					Msg("Failed to start local file server")
+			}
+		}()
+
+		ops["file server"] = func(ctx context.Context) error {
+			return fileServer.Shutdown(ctx)
+		}
+	}
+
+	if conf.App.MetricsPort != 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/vars", expvar.Handler())
+		metricsServer := &http.Server{
+			Addr:    fmt.Sprintf(":%v", conf.App.MetricsPort),
+			Handler: mux,
+		}
+
+		go func() {
+			log.Info().
+				Str("service", "file").
+				Msgf("Metrics server starting at port %v", conf.App.MetricsPort)
+
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal().
+					Err(err).
+					Str("service", "file").
+					Msg("Failed to start metrics server")
+			}
+		}()
+
+		ops["metrics server"] = func(ctx context.Context) error {
+			return metricsServer.Shutdown(ctx)
+		}
+	}
+
+	wait := gracefulShutdown(context.Background(), 2*time.Second, ops)
 
 	<-wait
 
@@ -3,11 +3,41 @@ package constant
 const InternalServerErrorMessage = "Internal server error"
 
 const UploadToBucketErrorMessage = "Error uploading to bucket client"
+const UploadMetadataRequiredErrorMessage = "Upload metadata required as the first message"
+const UploadChunkRequiredErrorMessage = "Only file chunks are allowed after the upload metadata"
+const ReceiveUploadErrorMessage = "Error receiving the uploaded file"
+const FileTooLargeErrorMessage = "File is too large"
+const EmptyFileErrorMessage = "File is empty"
+const UnsupportedImageTypeErrorMessage = "Only JPEG, PNG, WebP, GIF and HEIC images are allowed"
+const CorruptImageErrorMessage = "Image is corrupt"
+const ImageDimensionsTooLargeErrorMessage = "Image dimensions are too large"
+const SanitizeImageErrorMessage = "Error removing image metadata"
+const HeicMetadataErrorMessage = "The metadata of this HEIC image cannot be removed, convert the image to JPEG"
+const DownloadFromBucketErrorMessage = "Error downloading from bucket client"
+const ObjectNotFoundErrorMessage = "Image file not found in bucket"
+const PresignUrlErrorMessage = "Error creating presigned url"
+const UploadNotConfirmedErrorMessage = "Image upload is not confirmed"
+const PendingImageNotFoundErrorMessage = "Image is not waiting for its upload, it was confirmed or deleted"
+const UploadedObjectNotFoundErrorMessage = "Uploaded file not found in bucket"
+const CreateImageVariantErrorMessage = "Error creating image variants"
 const DeleteFromBucketErrorMessage = "Error deleting from bucket client"
 
 const ImageNotFoundErrorMessage = "Image not found"
 const CreateImageErrorMessage = "Error creating image in db"
+const UpdateImageErrorMessage = "Error updating image in db"
 const DeleteImageErrorMessage = "Error deleting image from db"
+const RestoreImageErrorMessage = "Error restoring image in db"
+const DeletedImageNotFoundErrorMessage = "Deleted image not found, it was never deleted or was already purged"
 const PrimaryKeyRequiredErrorMessage = "UUID Primary key (petId) required"
 const PetIdNotUUIDErrorMessage = "Pet id is not uuid"
 const PetIdNotFoundErrorMessage = "Pet id not found"
+const ImageIdNotUUIDErrorMessage = "Image id is not uuid"
+const TooManyIdsErrorMessage = "Too many ids"
+const InvalidCursorErrorMessage = "Cursor is invalid"
+const InvalidImageFilterErrorMessage = "Image filter is invalid"
+const PageSizeTooLargeErrorMessage = "Page size is too large"
+const SimilarImagesQueryRequiredErrorMessage = "Pet id or image id required"
+const ImageNotAssignedErrorMessage = "Image is not assigned to a pet"
+const ImageIdsRequiredErrorMessage = "Image ids required"
+const SamePetErrorMessage = "Images cannot be moved to the same pet"
+const ReorderIdsMismatchErrorMessage = "Ids must list every image of the pet once"
@@ -0,0 +1,21 @@
+package constant
+
+type ImageStatus string
+
+const (
+	// PendingImageStatus is an image whose upload url was issued but whose upload is not confirmed yet.
+	PendingImageStatus ImageStatus = "pending"
+	ReadyImageStatus   ImageStatus = "ready"
+	// MissingImageStatus is a stored image whose objects were not found in the bucket by the reconciliation job.
+	MissingImageStatus ImageStatus = "missing"
+)
+
+// CompensationReason is why the object of a failed compensation has to be deleted from the bucket.
+type CompensationReason string
+
+const (
+	// UploadCompensationReason is an object uploaded for an image that could not be stored.
+	UploadCompensationReason CompensationReason = "upload"
+	// PurgeCompensationReason is an object of a purged image that no other image uses.
+	PurgeCompensationReason CompensationReason = "purge"
+)
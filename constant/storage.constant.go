@@ -0,0 +1,7 @@
+package constant
+
+const (
+	S3StorageDriver     = "s3"
+	LocalStorageDriver  = "local"
+	MemoryStorageDriver = "memory"
+)
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// JanitorLockKey is the postgres advisory lock held by the replica that runs the janitor job.
+	JanitorLockKey = 7_242_002
+	// ReconciliationLockKey is the postgres advisory lock held by the replica that runs the reconciliation job.
+	ReconciliationLockKey = 7_242_003
+)
+
+// AdvisoryLock is a postgres advisory lock that is shared by every replica using the same database.
+type AdvisoryLock struct {
+	db  *gorm.DB
+	key int64
+}
+
+func NewAdvisoryLock(db *gorm.DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{db: db, key: key}
+}
+
+// TryRun runs fn while holding the lock and is false without running it when another replica holds the lock.
+// The lock is scoped to a transaction, so it is released when fn returns or the connection is lost, fn does
+// its own queries outside of that transaction.
+func (l *AdvisoryLock) TryRun(ctx context.Context, fn func() error) (bool, error) {
+	acquired := false
+	err := l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", l.key).Scan(&acquired).Error
+		if err != nil || !acquired {
+			return err
+		}
+
+		return fn()
+	})
+
+	return acquired, err
+}
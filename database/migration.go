@@ -0,0 +1,97 @@
+package database
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// migrationLockKey is the postgres advisory lock held while a migration runs so that
+// replicas starting at the same time do not apply the same migration twice.
+const migrationLockKey = 7_242_001
+
+type migration struct {
+	ID      string
+	Migrate func(tx *gorm.DB) error
+}
+
+type schemaMigration struct {
+	ID        string    `gorm:"primaryKey"`
+	AppliedAt time.Time `gorm:"type:timestamp;autoCreateTime"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrations are applied in order after AutoMigrate and each of them runs at most once.
+// Append new entries to the end of the list and never edit one that has been released.
+var migrations = []migration{
+	{
+		ID: "0001_images_pet_id_non_unique",
+		Migrate: func(tx *gorm.DB) error {
+			// the old unique index only allowed a single image per pet
+			if err := tx.Exec(`DROP INDEX IF EXISTS "idx_name"`).Error; err != nil {
+				return err
+			}
+
+			// the relationship is now owned by model.Pet and its constraint is recreated as fk_pets_images
+			return tx.Exec(`ALTER TABLE "images" DROP CONSTRAINT IF EXISTS "fk_images_pet"`).Error
+		},
+	},
+	{
+		ID: "0002_deletion_outbox_into_failed_compensations",
+		Migrate: func(tx *gorm.DB) error {
+			// the objects of purged images are deleted by the compensation job now
+			if !tx.Migrator().HasTable("deletion_outbox") {
+				return nil
+			}
+
+			err := tx.Exec(`INSERT INTO "failed_compensations" ("id", "created_at", "updated_at", "deleted_at", "object_key", "checksum", "reason", "attempts", "last_error") ` +
+				`SELECT "id", "created_at", "updated_at", "deleted_at", "object_key", "checksum", 'purge', "attempts", "last_error" FROM "deletion_outbox"`).Error
+			if err != nil {
+				return err
+			}
+
+			return tx.Exec(`DROP TABLE "deletion_outbox"`).Error
+		},
+	},
+}
+
+func runMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return errors.Wrap(err, "error occurs while creating the migration table")
+	}
+
+	for _, m := range migrations {
+		if err := applyMigration(db, m); err != nil {
+			return errors.Wrapf(err, "error occurs while running migration %v", m.ID)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration runs the migration unless it was applied already, it is recorded in the same transaction.
+func applyMigration(db *gorm.DB, m migration) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&schemaMigration{}).Where("id = ?", m.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := m.Migrate(tx); err != nil {
+			return err
+		}
+
+		return tx.Create(&schemaMigration{ID: m.ID}).Error
+	})
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// MigrationTest runs the migrations on sqlmock, the statements have to match exactly.
+type MigrationTest struct {
+	suite.Suite
+	db   *gorm.DB
+	mock sqlmock.Sqlmock
+}
+
+func TestMigration(t *testing.T) {
+	suite.Run(t, new(MigrationTest))
+}
+
+func (t *MigrationTest) SetupTest() {
+	conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.Nil(t.T(), err)
+	t.T().Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	assert.Nil(t.T(), err)
+
+	t.db, t.mock = db, mock
+}
+
+func (t *MigrationTest) TestMigrationIdsUnique() {
+	ids := map[string]bool{}
+	for _, m := range migrations {
+		assert.False(t.T(), ids[m.ID], m.ID)
+		ids[m.ID] = true
+	}
+}
+
+func (t *MigrationTest) TestApplyPetIdNonUnique() {
+	t.expectPending("0001_images_pet_id_non_unique")
+	t.mock.ExpectExec(`DROP INDEX IF EXISTS "idx_name"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	t.mock.ExpectExec(`ALTER TABLE "images" DROP CONSTRAINT IF EXISTS "fk_images_pet"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	t.expectApplied("0001_images_pet_id_non_unique")
+
+	err := applyMigration(t.db, migrations[0])
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), t.mock.ExpectationsWereMet())
+}
+
+func (t *MigrationTest) TestApplyDeletionOutbox() {
+	t.expectPending("0002_deletion_outbox_into_failed_compensations")
+	t.expectHasTable("deletion_outbox", true)
+	t.mock.ExpectExec(`INSERT INTO "failed_compensations" ("id", "created_at", "updated_at", "deleted_at", "object_key", "checksum", "reason", "attempts", "last_error") ` +
+		`SELECT "id", "created_at", "updated_at", "deleted_at", "object_key", "checksum", 'purge', "attempts", "last_error" FROM "deletion_outbox"`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	t.mock.ExpectExec(`DROP TABLE "deletion_outbox"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	t.expectApplied("0002_deletion_outbox_into_failed_compensations")
+
+	err := applyMigration(t.db, migrations[1])
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), t.mock.ExpectationsWereMet())
+}
+
+func (t *MigrationTest) TestApplyDeletionOutboxWithoutTable() {
+	t.expectPending("0002_deletion_outbox_into_failed_compensations")
+	t.expectHasTable("deletion_outbox", false)
+	t.expectApplied("0002_deletion_outbox_into_failed_compensations")
+
+	err := applyMigration(t.db, migrations[1])
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), t.mock.ExpectationsWereMet())
+}
+
+func (t *MigrationTest) TestApplyAppliedMigration() {
+	t.mock.ExpectBegin()
+	t.mock.ExpectExec(`SELECT pg_advisory_xact_lock($1)`).WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	t.mock.ExpectQuery(`SELECT count(*) FROM "schema_migrations" WHERE id = $1`).
+		WithArgs("0001_images_pet_id_non_unique").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	t.mock.ExpectCommit()
+
+	err := applyMigration(t.db, migrations[0])
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), t.mock.ExpectationsWereMet())
+}
+
+func (t *MigrationTest) TestApplyMigrationFailed() {
+	t.expectPending("0001_images_pet_id_non_unique")
+	t.mock.ExpectExec(`DROP INDEX IF EXISTS "idx_name"`).WillReturnError(errors.New("permission denied"))
+	t.mock.ExpectRollback()
+
+	err := applyMigration(t.db, migrations[0])
+
+	assert.EqualError(t.T(), err, "permission denied")
+	assert.Nil(t.T(), t.mock.ExpectationsWereMet())
+}
+
+// expectPending expects the migration lock to be taken and the migration to be looked up and not found.
+func (t *MigrationTest) expectPending(id string) {
+	t.mock.ExpectBegin()
+	t.mock.ExpectExec(`SELECT pg_advisory_xact_lock($1)`).WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	t.mock.ExpectQuery(`SELECT count(*) FROM "schema_migrations" WHERE id = $1`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+}
+
+// expectApplied expects the migration to be recorded and its transaction to be committed.
+func (t *MigrationTest) expectApplied(id string) {
+	t.mock.ExpectExec(`INSERT INTO "schema_migrations" ("id","applied_at") VALUES ($1,$2)`).
+		WithArgs(id, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	t.mock.ExpectCommit()
+}
+
+func (t *MigrationTest) expectHasTable(table string, exists bool) {
+	count := 0
+	if exists {
+		count = 1
+	}
+
+	t.mock.ExpectQuery(`SELECT count(*) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA() AND table_name = $1 AND table_type = $2`).
+		WithArgs(table, "BASE TABLE").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
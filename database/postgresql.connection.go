@@ -25,7 +25,12 @@ func InitPostgresDatabase(conf *cfgldr.Database, isDebug bool) (db *gorm.DB, err
 		return nil, err
 	}
 
-	err = db.AutoMigrate(&model.Image{}, &model.Pet{})
+	err = db.AutoMigrate(&model.Image{}, &model.ImageVariant{}, &model.ImageObject{}, &model.FailedCompensation{}, &model.Pet{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = runMigrations(db)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,99 @@
+package job
+
+import (
+	"context"
+
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/internal/model"
+	"github.com/isd-sgcu/johnjud-file/pkg/client/bucket"
+	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
+	"github.com/rs/zerolog/log"
+)
+
+type compensationJob struct {
+	client     bucket.Client
+	repository image.Repository
+	conf       cfgldr.Compensation
+}
+
+// NewCompensationJob creates the job that deletes the objects of the uploads whose image could not be stored
+// and whose delete failed at upload time, and the objects queued by purged images.
+func NewCompensationJob(client bucket.Client, repository image.Repository, conf cfgldr.Compensation) Job {
+	return &compensationJob{
+		client:     client,
+		repository: repository,
+		conf:       conf,
+	}
+}
+
+func (j *compensationJob) RunOnce(ctx context.Context) error {
+	var compensations []*model.FailedCompensation
+	err := j.repository.FindFailedCompensations(j.conf.BatchSize, &compensations)
+	if err != nil {
+		return err
+	}
+
+	for _, compensation := range compensations {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err = j.compensate(compensation)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compensate deletes the object unless an image or an upload uses the content addressed object of its checksum,
+// the checksum is locked meanwhile so that an upload of the same file cannot start using it before it is deleted.
+// The record is kept with the error when the delete fails again.
+func (j *compensationJob) compensate(compensation *model.FailedCompensation) error {
+	var deleteErr error
+	deleteObject := func() error {
+		deleteErr = j.client.Delete(compensation.ObjectKey)
+		if deleteErr == bucket.ErrObjectNotFound {
+			deleteErr = nil
+		}
+
+		return deleteErr
+	}
+
+	// the images stored before the objects were content addressed have no checksum
+	referenced := false
+	var err error
+	if compensation.Checksum != "" {
+		referenced, err = j.repository.DeleteUnreferencedObject(compensation.Checksum, deleteObject)
+	} else {
+		err = deleteObject()
+	}
+	if deleteErr != nil {
+		log.Warn().Err(deleteErr).
+			Str("service", "job").
+			Str("module", "compensation").
+			Str("objectKey", compensation.ObjectKey).
+			Str("reason", string(compensation.Reason)).
+			Int("attempts", compensation.Attempts+1).
+			Msg("Error deleting the object of a failed compensation")
+
+		return j.repository.UpdateFailedCompensation(compensation.ID.String(), &model.FailedCompensation{
+			Attempts:  compensation.Attempts + 1,
+			LastError: deleteErr.Error(),
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("service", "job").
+		Str("module", "compensation").
+		Str("objectKey", compensation.ObjectKey).
+		Str("reason", string(compensation.Reason)).
+		Bool("referenced", referenced).
+		Msg("Compensated the object of a failed compensation")
+
+	return j.repository.DeleteFailedCompensation(compensation.ID.String())
+}
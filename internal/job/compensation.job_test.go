@@ -0,0 +1,237 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/client/bucket"
+	"github.com/isd-sgcu/johnjud-file/constant"
+	"github.com/isd-sgcu/johnjud-file/internal/model"
+	mock_bucket "github.com/isd-sgcu/johnjud-file/mocks/client/bucket"
+	mock_image "github.com/isd-sgcu/johnjud-file/mocks/repository/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CompensationJobTest struct {
+	suite.Suite
+	conf          cfgldr.Compensation
+	compensation  *model.FailedCompensation
+	compensations []*model.FailedCompensation
+}
+
+func TestCompensationJob(t *testing.T) {
+	suite.Run(t, new(CompensationJobTest))
+}
+
+func (t *CompensationJobTest) SetupTest() {
+	t.conf = cfgldr.Compensation{BatchSize: 10}
+	t.compensation = &model.FailedCompensation{
+		Base:      model.Base{ID: uuid.New()},
+		ObjectKey: "checksum_160w",
+		Checksum:  "checksum",
+		Reason:    constant.UploadCompensationReason,
+		Attempts:  3,
+		LastError: "bucket unavailable",
+	}
+	t.compensations = []*model.FailedCompensation{t.compensation}
+}
+
+func (t *CompensationJobTest) TestRunOnceDeletesObject() {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "deleted", err: nil},
+		{name: "already deleted", err: bucket.ErrObjectNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			controller := gomock.NewController(t.T())
+
+			imageRepo := &mock_image.ImageRepositoryMock{}
+			bucketClient := mock_bucket.NewMockClient(controller)
+			imageRepo.On("FindFailedCompensations", 10, mock.AnythingOfType("*[]*model.FailedCompensation")).Return(&t.compensations, nil)
+			imageRepo.On("DeleteUnreferencedObject", "checksum").Return(false, nil)
+			imageRepo.On("DeleteFailedCompensation", t.compensation.ID.String()).Return(nil)
+			bucketClient.EXPECT().Delete("checksum_160w").Return(test.err)
+
+			err := NewCompensationJob(bucketClient, imageRepo, t.conf).RunOnce(context.Background())
+
+			assert.Nil(t.T(), err)
+			imageRepo.AssertExpectations(t.T())
+		})
+	}
+}
+
+func (t *CompensationJobTest) TestRunOnceWithoutChecksum() {
+	controller := gomock.NewController(t.T())
+
+	// an object of a purged image stored before the objects were content addressed
+	t.compensation.Checksum = ""
+	t.compensation.Reason = constant.PurgeCompensationReason
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	imageRepo.On("FindFailedCompensations", 10, mock.AnythingOfType("*[]*model.FailedCompensation")).Return(&t.compensations, nil)
+	imageRepo.On("DeleteFailedCompensation", t.compensation.ID.String()).Return(nil)
+	bucketClient.EXPECT().Delete("checksum_160w").Return(nil)
+
+	err := NewCompensationJob(bucketClient, imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	imageRepo.AssertExpectations(t.T())
+	imageRepo.AssertNotCalled(t.T(), "DeleteUnreferencedObject", mock.Anything)
+}
+
+func (t *CompensationJobTest) TestRunOnceKeepsReferencedObject() {
+	controller := gomock.NewController(t.T())
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	imageRepo.On("FindFailedCompensations", 10, mock.AnythingOfType("*[]*model.FailedCompensation")).Return(&t.compensations, nil)
+	imageRepo.On("DeleteUnreferencedObject", "checksum").Return(true, nil)
+	imageRepo.On("DeleteFailedCompensation", t.compensation.ID.String()).Return(nil)
+
+	err := NewCompensationJob(bucketClient, imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *CompensationJobTest) TestRunOnceDeleteFailed() {
+	controller := gomock.NewController(t.T())
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	imageRepo.On("FindFailedCompensations", 10, mock.AnythingOfType("*[]*model.FailedCompensation")).Return(&t.compensations, nil)
+	imageRepo.On("DeleteUnreferencedObject", "checksum").Return(false, nil)
+	imageRepo.On("UpdateFailedCompensation", t.compensation.ID.String(), &model.FailedCompensation{Attempts: 4, LastError: "access denied"}).Return(nil)
+	bucketClient.EXPECT().Delete("checksum_160w").Return(errors.New("access denied"))
+
+	err := NewCompensationJob(bucketClient, imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	imageRepo.AssertExpectations(t.T())
+	imageRepo.AssertNotCalled(t.T(), "DeleteFailedCompensation", mock.Anything)
+}
+
+func (t *CompensationJobTest) TestRunOnceRepoFailed() {
+	controller := gomock.NewController(t.T())
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	imageRepo.On("FindFailedCompensations", 10, mock.AnythingOfType("*[]*model.FailedCompensation")).Return(&t.compensations, nil)
+	imageRepo.On("DeleteUnreferencedObject", "checksum").Return(false, errors.New("database unavailable"))
+
+	err := NewCompensationJob(bucketClient, imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.EqualError(t.T(), err, "database unavailable")
+}
+
+func (t *CompensationJobTest) TestRunOnceUploadOfSameFileWaits() {
+	controller := gomock.NewController(t.T())
+
+	objects := newObjectRows()
+	bucketClient := mock_bucket.NewMockClient(controller)
+	objects.On("FindFailedCompensations", 10, mock.AnythingOfType("*[]*model.FailedCompensation")).Return(&t.compensations, nil)
+	objects.On("DeleteFailedCompensation", t.compensation.ID.String()).Return(nil)
+
+	deleting, resume := make(chan struct{}), make(chan struct{})
+	bucketClient.EXPECT().Delete("checksum_160w").DoAndReturn(func(string) error {
+		close(deleting)
+		<-resume
+		objects.record("deleted")
+
+		return nil
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- NewCompensationJob(bucketClient, objects, t.conf).RunOnce(context.Background())
+	}()
+	<-deleting
+
+	// an upload of the same file starts while the object is being deleted
+	reserved := make(chan struct{})
+	go func() {
+		assert.Nil(t.T(), objects.ReserveObject("checksum", "checksum"))
+		objects.record("reserved")
+		close(reserved)
+	}()
+
+	select {
+	case <-reserved:
+		t.Fail("the upload reserved the object while it was being deleted")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(resume)
+	<-reserved
+
+	assert.Nil(t.T(), <-done)
+	assert.Equal(t.T(), []string{"deleted", "reserved"}, objects.events)
+	objects.AssertExpectations(t.T())
+}
+
+func (t *CompensationJobTest) TestRunOnceKeepsObjectOfUploadInProgress() {
+	controller := gomock.NewController(t.T())
+
+	objects := newObjectRows()
+	bucketClient := mock_bucket.NewMockClient(controller)
+	objects.On("FindFailedCompensations", 10, mock.AnythingOfType("*[]*model.FailedCompensation")).Return(&t.compensations, nil)
+	objects.On("DeleteFailedCompensation", t.compensation.ID.String()).Return(nil)
+	// another upload of the same file reserved the object before the job got to it
+	assert.Nil(t.T(), objects.ReserveObject("checksum", "checksum"))
+
+	err := NewCompensationJob(bucketClient, objects, t.conf).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	objects.AssertExpectations(t.T())
+}
+
+// objectRows stands in for the image_objects rows of a repository. DeleteUnreferencedObject locks the checksum
+// until it returns like SELECT ... FOR UPDATE locks its row, so ReserveObject waits for it like the upsert does.
+type objectRows struct {
+	*mock_image.ImageRepositoryMock
+	lock     sync.Mutex
+	refCount map[string]int
+	mu       sync.Mutex
+	events   []string
+}
+
+func newObjectRows() *objectRows {
+	return &objectRows{ImageRepositoryMock: &mock_image.ImageRepositoryMock{}, refCount: map[string]int{}}
+}
+
+func (r *objectRows) ReserveObject(checksum string, _ string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.refCount[checksum]++
+	return nil
+}
+
+func (r *objectRows) DeleteUnreferencedObject(checksum string, fn func() error) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.refCount[checksum] > 0 {
+		return true, nil
+	}
+
+	return false, fn()
+}
+
+func (r *objectRows) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
@@ -0,0 +1,144 @@
+package job
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/constant"
+	"github.com/isd-sgcu/johnjud-file/internal/model"
+	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// janitorMetrics are published at /debug/vars, the counters add up the rounds since the process started.
+var janitorMetrics = expvar.NewMap("janitor")
+
+type janitorJob struct {
+	repository image.Repository
+	conf       cfgldr.Janitor
+}
+
+// NewJanitorJob creates the job that deletes the images left unassigned to a pet for longer than the TTL and the
+// pending images whose upload was not confirmed within the pending TTL. They can be restored until they are
+// purged like any other deleted image.
+func NewJanitorJob(repository image.Repository, conf cfgldr.Janitor) Job {
+	return &janitorJob{
+		repository: repository,
+		conf:       conf,
+	}
+}
+
+// sweepCounts are the images that a round of the janitor found and deleted.
+type sweepCounts struct {
+	found, deleted, failed int
+}
+
+func (j *janitorJob) RunOnce(ctx context.Context) error {
+	start := time.Now()
+	before := start.Add(-j.conf.TTL)
+
+	assigned := false
+	unassigned, err := j.sweep(ctx, "unassigned image", image.Filter{Assigned: &assigned, UpdatedBefore: before}, func(id string) error {
+		return j.repository.DeleteUnassigned(id, before)
+	})
+
+	// the pending images are kept while the upload url is valid, they are swept regardless of their pet
+	var expired sweepCounts
+	expiredBefore := start.Add(-j.conf.PendingTTL)
+	if err == nil && j.conf.PendingTTL > 0 {
+		expired, err = j.sweep(ctx, "expired upload", image.Filter{Status: constant.PendingImageStatus, CreatedBefore: expiredBefore}, func(id string) error {
+			return j.repository.DeleteExpiredUpload(id, expiredBefore)
+		})
+	}
+
+	janitorMetrics.Add("runs", 1)
+	janitorMetrics.Add("found", int64(unassigned.found+expired.found))
+	janitorMetrics.Add("deleted", int64(unassigned.deleted+expired.deleted))
+	janitorMetrics.Add("expired_uploads", int64(expired.deleted))
+	janitorMetrics.Add("failed", int64(unassigned.failed+expired.failed))
+	if err != nil {
+		janitorMetrics.Add("errors", 1)
+		return err
+	}
+
+	lastRun := new(expvar.Int)
+	lastRun.Set(start.Unix())
+	janitorMetrics.Set("last_success_unix", lastRun)
+
+	log.Info().
+		Str("service", "job").
+		Str("module", "janitor").
+		Time("unassignedBefore", before).
+		Int("found", unassigned.found).
+		Int("deleted", unassigned.deleted).
+		Int("failed", unassigned.failed).
+		Int("expiredUploads", expired.deleted).
+		Int("expiredUploadsFailed", expired.failed).
+		Dur("duration", time.Since(start)).
+		Msg("Deleted the unassigned images and the expired uploads")
+
+	return nil
+}
+
+// sweep deletes the images of the filter with del, an image that del does not find was changed since it was
+// listed and is skipped.
+func (j *janitorJob) sweep(ctx context.Context, kind string, filter image.Filter, del func(id string) error) (sweepCounts, error) {
+	var result sweepCounts
+	err := j.collect(ctx, filter, func(img *model.Image) {
+		result.found++
+
+		err := del(img.ID.String())
+		if err == gorm.ErrRecordNotFound {
+			return
+		}
+		if err != nil {
+			result.failed++
+			log.Warn().Err(err).
+				Str("service", "job").
+				Str("module", "janitor").
+				Str("id", img.ID.String()).
+				Msgf("Error deleting an %v", kind)
+
+			return
+		}
+
+		result.deleted++
+	})
+
+	return result, err
+}
+
+// collect pages through the images of the filter.
+func (j *janitorJob) collect(ctx context.Context, filter image.Filter, fn func(img *model.Image)) error {
+	query := &image.ListQuery{
+		Filter:    filter,
+		Ascending: true,
+		Limit:     j.conf.BatchSize,
+	}
+
+	for {
+		var images []*model.Image
+		err := j.repository.List(query, &images)
+		if err != nil {
+			return err
+		}
+
+		for _, img := range images {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			fn(img)
+		}
+
+		if len(images) < query.Limit {
+			return nil
+		}
+
+		last := images[len(images)-1]
+		query.After = &image.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
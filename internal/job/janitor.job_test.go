@@ -0,0 +1,161 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/constant"
+	"github.com/isd-sgcu/johnjud-file/internal/model"
+	mock_image "github.com/isd-sgcu/johnjud-file/mocks/repository/image"
+	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type JanitorJobTest struct {
+	suite.Suite
+	conf   cfgldr.Janitor
+	images []*model.Image
+}
+
+func TestJanitorJob(t *testing.T) {
+	suite.Run(t, new(JanitorJobTest))
+}
+
+func (t *JanitorJobTest) SetupTest() {
+	t.conf = cfgldr.Janitor{TTL: 72 * time.Hour, BatchSize: 10}
+	t.images = []*model.Image{
+		{Base: model.Base{ID: uuid.New()}},
+		{Base: model.Base{ID: uuid.New()}},
+		{Base: model.Base{ID: uuid.New()}},
+	}
+}
+
+func (t *JanitorJobTest) TestRunOnceDeletesUnassignedImages() {
+	deleted := janitorCounter("deleted")
+	failed := janitorCounter("failed")
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("List", mock.MatchedBy(func(query *image.ListQuery) bool {
+		return query.Filter.Assigned != nil && !*query.Filter.Assigned && time.Since(query.Filter.UpdatedBefore) >= t.conf.TTL
+	}), mock.AnythingOfType("*[]*model.Image")).Return(&t.images, nil)
+	imageRepo.On("DeleteUnassigned", t.images[0].ID.String(), mock.AnythingOfType("time.Time")).Return(nil)
+	imageRepo.On("DeleteUnassigned", t.images[1].ID.String(), mock.AnythingOfType("time.Time")).Return(gorm.ErrRecordNotFound)
+	imageRepo.On("DeleteUnassigned", t.images[2].ID.String(), mock.AnythingOfType("time.Time")).Return(errors.New("database unavailable"))
+
+	err := NewJanitorJob(imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), deleted+1, janitorCounter("deleted"))
+	assert.Equal(t.T(), failed+1, janitorCounter("failed"))
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *JanitorJobTest) TestRunOnceDeletesExpiredUploads() {
+	t.conf.PendingTTL = time.Hour
+	expired := janitorCounter("expired_uploads")
+	none := []*model.Image{}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("List", mock.MatchedBy(func(query *image.ListQuery) bool {
+		return query.Filter.Assigned != nil
+	}), mock.AnythingOfType("*[]*model.Image")).Return(&none, nil)
+	imageRepo.On("List", mock.MatchedBy(func(query *image.ListQuery) bool {
+		return query.Filter.Assigned == nil && query.Filter.Status == constant.PendingImageStatus && time.Since(query.Filter.CreatedBefore) >= t.conf.PendingTTL
+	}), mock.AnythingOfType("*[]*model.Image")).Return(&t.images, nil)
+	imageRepo.On("DeleteExpiredUpload", t.images[0].ID.String(), mock.AnythingOfType("time.Time")).Return(nil)
+	imageRepo.On("DeleteExpiredUpload", t.images[1].ID.String(), mock.AnythingOfType("time.Time")).Return(nil)
+	imageRepo.On("DeleteExpiredUpload", t.images[2].ID.String(), mock.AnythingOfType("time.Time")).Return(gorm.ErrRecordNotFound)
+
+	err := NewJanitorJob(imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), expired+2, janitorCounter("expired_uploads"))
+	imageRepo.AssertExpectations(t.T())
+	imageRepo.AssertNotCalled(t.T(), "DeleteUnassigned", mock.Anything, mock.Anything)
+}
+
+func (t *JanitorJobTest) TestRunOnceKeepsPendingWithoutPendingTTL() {
+	none := []*model.Image{}
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("List", mock.AnythingOfType("*image.ListQuery"), mock.AnythingOfType("*[]*model.Image")).Return(&none, nil)
+
+	err := NewJanitorJob(imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	imageRepo.AssertNumberOfCalls(t.T(), "List", 1)
+	imageRepo.AssertNotCalled(t.T(), "DeleteExpiredUpload", mock.Anything, mock.Anything)
+}
+
+func (t *JanitorJobTest) TestRunOncePagesThroughImages() {
+	t.conf.BatchSize = 2
+	first, last := t.images[:2], t.images[2:]
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("List", mock.MatchedBy(func(query *image.ListQuery) bool { return query.After == nil }), mock.AnythingOfType("*[]*model.Image")).Return(&first, nil).Once()
+	imageRepo.On("List", mock.MatchedBy(func(query *image.ListQuery) bool { return query.After != nil && query.After.ID == t.images[1].ID }), mock.AnythingOfType("*[]*model.Image")).Return(&last, nil).Once()
+	imageRepo.On("DeleteUnassigned", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+
+	err := NewJanitorJob(imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	imageRepo.AssertExpectations(t.T())
+	imageRepo.AssertNumberOfCalls(t.T(), "DeleteUnassigned", 3)
+}
+
+func (t *JanitorJobTest) TestRunOnceRepoFailed() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("List", mock.AnythingOfType("*image.ListQuery"), mock.AnythingOfType("*[]*model.Image")).Return(nil, errors.New("database unavailable"))
+
+	err := NewJanitorJob(imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.EqualError(t.T(), err, "database unavailable")
+	imageRepo.AssertNotCalled(t.T(), "DeleteUnassigned", mock.Anything, mock.Anything)
+}
+
+func (t *JanitorJobTest) TestExclusiveSkipsWithoutLock() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+
+	err := Exclusive("janitor", &lockStub{acquired: false}, NewJanitorJob(imageRepo, t.conf)).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	imageRepo.AssertNotCalled(t.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (t *JanitorJobTest) TestExclusiveRunsWithLock() {
+	images := []*model.Image{}
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("List", mock.AnythingOfType("*image.ListQuery"), mock.AnythingOfType("*[]*model.Image")).Return(&images, nil)
+
+	err := Exclusive("janitor", &lockStub{acquired: true}, NewJanitorJob(imageRepo, t.conf)).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	imageRepo.AssertExpectations(t.T())
+}
+
+type lockStub struct {
+	acquired bool
+}
+
+func (l *lockStub) TryRun(_ context.Context, fn func() error) (bool, error) {
+	if !l.acquired {
+		return false, nil
+	}
+
+	return true, fn()
+}
+
+func janitorCounter(key string) int64 {
+	counter, ok := janitorMetrics.Get(key).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+
+	return counter.Value()
+}
@@ -0,0 +1,75 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Job interface {
+	// RunOnce does one round of the work of the job.
+	RunOnce(ctx context.Context) error
+}
+
+// Run runs the job right away and then every interval until the context is done, a round that fails is logged
+// and the work is left for the next round. The job is disabled when the interval is not positive.
+func Run(ctx context.Context, name string, interval time.Duration, job Job) {
+	if interval <= 0 {
+		log.Info().
+			Str("service", "job").
+			Str("module", name).
+			Msg("Job is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := job.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).
+				Str("service", "job").
+				Str("module", name).
+				Msg("Error running job")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Lock is held by at most one replica at a time.
+type Lock interface {
+	// TryRun runs fn while holding the lock and is false without running it when the lock is held elsewhere.
+	TryRun(ctx context.Context, fn func() error) (bool, error)
+}
+
+type exclusiveJob struct {
+	name string
+	lock Lock
+	job  Job
+}
+
+// Exclusive makes the job run only in the replica that holds the lock, the other replicas skip the round.
+func Exclusive(name string, lock Lock, job Job) Job {
+	return &exclusiveJob{name: name, lock: lock, job: job}
+}
+
+func (j *exclusiveJob) RunOnce(ctx context.Context) error {
+	acquired, err := j.lock.TryRun(ctx, func() error {
+		return j.job.RunOnce(ctx)
+	})
+	if err == nil && !acquired {
+		log.Debug().
+			Str("service", "job").
+			Str("module", j.name).
+			Msg("Skipped the round, another replica holds the lock")
+	}
+
+	return err
+}
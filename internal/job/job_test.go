@@ -0,0 +1,55 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RunTest struct {
+	suite.Suite
+}
+
+func TestRun(t *testing.T) {
+	suite.Run(t, new(RunTest))
+}
+
+func (t *RunTest) TestRunUntilCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	rounds := 0
+	job := jobFunc(func(context.Context) error {
+		rounds++
+		if rounds == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	Run(ctx, "test", time.Millisecond, job)
+
+	assert.Equal(t.T(), 2, rounds)
+}
+
+func (t *RunTest) TestRunDisabled() {
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		rounds := 0
+		job := jobFunc(func(context.Context) error {
+			rounds++
+			return nil
+		})
+
+		// returns right away instead of panicking in time.NewTicker
+		Run(context.Background(), "test", interval, job)
+
+		assert.Zero(t.T(), rounds)
+	}
+}
+
+type jobFunc func(ctx context.Context) error
+
+func (f jobFunc) RunOnce(ctx context.Context) error {
+	return f(ctx)
+}
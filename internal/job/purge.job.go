@@ -0,0 +1,65 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/internal/model"
+	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type purgeJob struct {
+	repository image.Repository
+	conf       cfgldr.Purge
+}
+
+// NewPurgeJob creates the job that permanently deletes the images deleted longer than the retention ago. Their
+// objects are queued for the compensation job once no other image uses them.
+func NewPurgeJob(repository image.Repository, conf cfgldr.Purge) Job {
+	return &purgeJob{
+		repository: repository,
+		conf:       conf,
+	}
+}
+
+func (j *purgeJob) RunOnce(ctx context.Context) error {
+	before := time.Now().Add(-j.conf.Retention)
+
+	var images []*model.Image
+	err := j.repository.FindDeleted(before, j.conf.BatchSize, &images)
+	if err != nil {
+		return err
+	}
+
+	purged := 0
+	for _, img := range images {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err = j.repository.Purge(img.ID.String(), before)
+		// restored or purged by another replica since it was found
+		if err == gorm.ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		purged++
+	}
+
+	if purged > 0 {
+		log.Info().
+			Str("service", "job").
+			Str("module", "purge").
+			Time("deletedBefore", before).
+			Int("purged", purged).
+			Msg("Purged the deleted images")
+	}
+
+	return nil
+}
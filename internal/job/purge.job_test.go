@@ -0,0 +1,72 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/internal/model"
+	mock_image "github.com/isd-sgcu/johnjud-file/mocks/repository/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type PurgeJobTest struct {
+	suite.Suite
+	conf   cfgldr.Purge
+	images []*model.Image
+}
+
+func TestPurgeJob(t *testing.T) {
+	suite.Run(t, new(PurgeJobTest))
+}
+
+func (t *PurgeJobTest) SetupTest() {
+	t.conf = cfgldr.Purge{Retention: 720 * time.Hour, BatchSize: 10}
+	t.images = []*model.Image{
+		{Base: model.Base{ID: uuid.New()}},
+		{Base: model.Base{ID: uuid.New()}},
+	}
+}
+
+func (t *PurgeJobTest) TestRunOncePurgesDeletedImages() {
+	var before time.Time
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindDeleted", mock.MatchedBy(func(in time.Time) bool {
+		before = in
+		return time.Since(in) >= t.conf.Retention
+	}), 10, mock.AnythingOfType("*[]*model.Image")).Return(&t.images, nil)
+	imageRepo.On("Purge", t.images[0].ID.String(), mock.MatchedBy(func(in time.Time) bool { return in.Equal(before) })).Return(nil)
+	imageRepo.On("Purge", t.images[1].ID.String(), mock.MatchedBy(func(in time.Time) bool { return in.Equal(before) })).Return(gorm.ErrRecordNotFound)
+
+	err := NewPurgeJob(imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.Nil(t.T(), err)
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *PurgeJobTest) TestRunOncePurgeFailed() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindDeleted", mock.AnythingOfType("time.Time"), 10, mock.AnythingOfType("*[]*model.Image")).Return(&t.images, nil)
+	imageRepo.On("Purge", t.images[0].ID.String(), mock.AnythingOfType("time.Time")).Return(errors.New("database unavailable"))
+
+	err := NewPurgeJob(imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.EqualError(t.T(), err, "database unavailable")
+	imageRepo.AssertNotCalled(t.T(), "Purge", t.images[1].ID.String(), mock.Anything)
+}
+
+func (t *PurgeJobTest) TestRunOnceRepoFailed() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindDeleted", mock.AnythingOfType("time.Time"), 10, mock.AnythingOfType("*[]*model.Image")).Return(nil, errors.New("database unavailable"))
+
+	err := NewPurgeJob(imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.EqualError(t.T(), err, "database unavailable")
+	imageRepo.AssertNotCalled(t.T(), "Purge", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,227 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/constant"
+	"github.com/isd-sgcu/johnjud-file/internal/model"
+	"github.com/isd-sgcu/johnjud-file/pkg/client/bucket"
+	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
+	"github.com/rs/zerolog/log"
+)
+
+type reconciliationJob struct {
+	client     bucket.Client
+	repository image.Repository
+	conf       cfgldr.Reconciliation
+}
+
+// reconciliationReport is the difference between the bucket and the db found by a run.
+type reconciliationReport struct {
+	// OrphanObjects are the keys of the objects in the bucket that the db does not know of.
+	OrphanObjects []string
+	// MissingImages are the ids of the ready images whose object or the object of a variant is not in the
+	// bucket.
+	MissingImages  []string
+	DeletedObjects int
+	FlaggedImages  int
+}
+
+// NewReconciliationJob creates the job that compares the objects in the bucket with the images in the db.
+func NewReconciliationJob(client bucket.Client, repository image.Repository, conf cfgldr.Reconciliation) Job {
+	return &reconciliationJob{
+		client:     client,
+		repository: repository,
+		conf:       conf,
+	}
+}
+
+func (j *reconciliationJob) RunOnce(ctx context.Context) error {
+	report, err := j.reconcile(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("service", "job").
+		Str("module", "reconciliation").
+		Bool("apply", j.conf.Apply).
+		Int("orphanObjects", len(report.OrphanObjects)).
+		Int("missingImages", len(report.MissingImages)).
+		Int("deletedObjects", report.DeletedObjects).
+		Int("flaggedImages", report.FlaggedImages).
+		Msg("Reconciled the bucket with the db")
+
+	return nil
+}
+
+// reconcile reads the keys known to the db before listing the bucket and only reports objects and images
+// older than the grace period, so that uploads in progress are never mistaken for orphans.
+func (j *reconciliationJob) reconcile(ctx context.Context) (*reconciliationReport, error) {
+	cutoff := time.Now().Add(-j.conf.GracePeriod)
+
+	var keys []string
+	err := j.repository.FindObjectKeys(&keys)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		known[key] = struct{}{}
+	}
+
+	report := &reconciliationReport{}
+	stored := map[string]struct{}{}
+	err = j.client.List(func(info *bucket.ObjectInfo) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		stored[info.Key] = struct{}{}
+		if _, ok := known[info.Key]; !ok && info.LastModified.Before(cutoff) {
+			report.OrphanObjects = append(report.OrphanObjects, info.Key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.MissingImages, err = j.findMissingImages(ctx, stored, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range report.OrphanObjects {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		deleted := j.conf.Apply && j.deleteOrphan(key, cutoff)
+		if deleted {
+			report.DeletedObjects++
+		}
+
+		log.Warn().
+			Str("service", "job").
+			Str("module", "reconciliation").
+			Str("objectKey", key).
+			Bool("deleted", deleted).
+			Msg("Found an object that is not known to the db")
+	}
+
+	for _, id := range report.MissingImages {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		flagged := j.conf.Apply && j.flagMissing(id)
+		if flagged {
+			report.FlaggedImages++
+		}
+
+		log.Warn().
+			Str("service", "job").
+			Str("module", "reconciliation").
+			Str("id", id).
+			Bool("flagged", flagged).
+			Msg("Found an image whose objects are missing from the bucket")
+	}
+
+	return report, nil
+}
+
+// findMissingImages pages through the ready images created before the cutoff and returns the ids of those with
+// an object that is not stored.
+func (j *reconciliationJob) findMissingImages(ctx context.Context, stored map[string]struct{}, cutoff time.Time) ([]string, error) {
+	var missing []string
+	query := &image.ListQuery{
+		Filter:    image.Filter{Status: constant.ReadyImageStatus, CreatedBefore: cutoff},
+		Ascending: true,
+		Limit:     j.conf.BatchSize,
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var images []*model.Image
+		err := j.repository.List(query, &images)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, img := range images {
+			if !isStored(stored, img) {
+				missing = append(missing, img.ID.String())
+			}
+		}
+
+		if len(images) < query.Limit {
+			return missing, nil
+		}
+
+		last := images[len(images)-1]
+		query.After = &image.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// deleteOrphan checks the object again right before deleting it, because an upload of the same file puts the
+// content addressed object again after it was listed.
+func (j *reconciliationJob) deleteOrphan(objectKey string, cutoff time.Time) bool {
+	info, err := j.client.Head(objectKey)
+	if err == bucket.ErrObjectNotFound || (err == nil && !info.LastModified.Before(cutoff)) {
+		return false
+	}
+
+	if err == nil {
+		err = j.client.Delete(objectKey)
+	}
+	if err != nil && err != bucket.ErrObjectNotFound {
+		log.Error().Err(err).
+			Str("service", "job").
+			Str("module", "reconciliation").
+			Str("objectKey", objectKey).
+			Msg("Error deleting an object that is not known to the db")
+
+		return false
+	}
+
+	return err == nil
+}
+
+// flagMissing marks the image as missing, which hides it from the pets and from the deduplication of uploads.
+func (j *reconciliationJob) flagMissing(id string) bool {
+	err := j.repository.Update(id, &model.Image{Status: constant.MissingImageStatus})
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "job").
+			Str("module", "reconciliation").
+			Str("id", id).
+			Msg("Error flagging an image whose objects are missing")
+
+		return false
+	}
+
+	return true
+}
+
+func isStored(stored map[string]struct{}, img *model.Image) bool {
+	keys := []string{img.ObjectKey}
+	for _, variant := range img.Variants {
+		keys = append(keys, variant.ObjectKey)
+	}
+
+	for _, key := range keys {
+		if _, ok := stored[key]; key != "" && !ok {
+			return false
+		}
+	}
+
+	return true
+}
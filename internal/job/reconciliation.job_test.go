@@ -0,0 +1,152 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/client/bucket"
+	"github.com/isd-sgcu/johnjud-file/constant"
+	"github.com/isd-sgcu/johnjud-file/internal/model"
+	mock_bucket "github.com/isd-sgcu/johnjud-file/mocks/client/bucket"
+	mock_image "github.com/isd-sgcu/johnjud-file/mocks/repository/image"
+	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReconciliationJobTest struct {
+	suite.Suite
+	conf    cfgldr.Reconciliation
+	old     time.Time
+	client  *bucket.MemoryClient
+	keys    []string
+	stored  *model.Image
+	missing *model.Image
+	images  []*model.Image
+}
+
+func TestReconciliationJob(t *testing.T) {
+	suite.Run(t, new(ReconciliationJobTest))
+}
+
+func (t *ReconciliationJobTest) SetupTest() {
+	t.conf = cfgldr.Reconciliation{GracePeriod: time.Hour, BatchSize: 10}
+	t.old = time.Now().Add(-2 * time.Hour)
+
+	t.client = bucket.NewMemoryClient()
+	for _, objectKey := range []string{"stored", "stored_160w", "orphan", "uploading"} {
+		_, _, err := t.client.Upload([]byte("image"), objectKey)
+		assert.Nil(t.T(), err)
+	}
+	for _, objectKey := range []string{"stored", "stored_160w", "orphan"} {
+		t.client.SetModTime(objectKey, t.old)
+	}
+	t.keys = []string{"stored", "stored_160w", "missing", "missing_160w"}
+
+	t.stored = &model.Image{
+		Base:      model.Base{ID: uuid.New(), CreatedAt: t.old},
+		ObjectKey: "stored",
+		Variants:  []*model.ImageVariant{{Width: 160, ObjectKey: "stored_160w"}},
+	}
+	t.missing = &model.Image{
+		Base:      model.Base{ID: uuid.New(), CreatedAt: t.old},
+		ObjectKey: "missing",
+		Variants:  []*model.ImageVariant{{Width: 160, ObjectKey: "missing_160w"}},
+	}
+	t.images = []*model.Image{t.stored, t.missing}
+}
+
+func (t *ReconciliationJobTest) TestReconcileDryRun() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindObjectKeys", mock.AnythingOfType("*[]string")).Return(&t.keys, nil)
+	imageRepo.On("List", mock.AnythingOfType("*image.ListQuery"), mock.AnythingOfType("*[]*model.Image")).Return(&t.images, nil)
+
+	report, err := NewReconciliationJob(t.client, imageRepo, t.conf).(*reconciliationJob).reconcile(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), []string{"orphan"}, report.OrphanObjects)
+	assert.Equal(t.T(), []string{t.missing.ID.String()}, report.MissingImages)
+	assert.Zero(t.T(), report.DeletedObjects)
+	assert.Zero(t.T(), report.FlaggedImages)
+	assert.Equal(t.T(), []string{"orphan", "stored", "stored_160w", "uploading"}, t.client.Keys())
+	imageRepo.AssertNotCalled(t.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (t *ReconciliationJobTest) TestReconcileApply() {
+	t.conf.Apply = true
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindObjectKeys", mock.AnythingOfType("*[]string")).Return(&t.keys, nil)
+	imageRepo.On("List", mock.AnythingOfType("*image.ListQuery"), mock.AnythingOfType("*[]*model.Image")).Return(&t.images, nil)
+	imageRepo.On("Update", t.missing.ID.String(), &model.Image{Status: constant.MissingImageStatus}).Return(&model.Image{}, nil)
+
+	report, err := NewReconciliationJob(t.client, imageRepo, t.conf).(*reconciliationJob).reconcile(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 1, report.DeletedObjects)
+	assert.Equal(t.T(), 1, report.FlaggedImages)
+	assert.Equal(t.T(), []string{"stored", "stored_160w", "uploading"}, t.client.Keys())
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *ReconciliationJobTest) TestReconcilePagesThroughImages() {
+	t.conf.BatchSize = 1
+	first, second, last := []*model.Image{t.stored}, []*model.Image{t.missing}, []*model.Image{}
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindObjectKeys", mock.AnythingOfType("*[]string")).Return(&t.keys, nil)
+	imageRepo.On("List", mock.MatchedBy(func(query *image.ListQuery) bool { return query.After == nil }), mock.AnythingOfType("*[]*model.Image")).Return(&first, nil).Once()
+	imageRepo.On("List", mock.MatchedBy(func(query *image.ListQuery) bool { return query.After != nil && query.After.ID == t.stored.ID }), mock.AnythingOfType("*[]*model.Image")).Return(&second, nil).Once()
+	imageRepo.On("List", mock.MatchedBy(func(query *image.ListQuery) bool { return query.After != nil && query.After.ID == t.missing.ID }), mock.AnythingOfType("*[]*model.Image")).Return(&last, nil).Once()
+
+	report, err := NewReconciliationJob(t.client, imageRepo, t.conf).(*reconciliationJob).reconcile(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), []string{t.missing.ID.String()}, report.MissingImages)
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *ReconciliationJobTest) TestReconcileKeepsReuploadedObject() {
+	t.conf.Apply = true
+	controller := gomock.NewController(t.T())
+
+	keys, images := []string{}, []*model.Image{}
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	imageRepo.On("FindObjectKeys", mock.AnythingOfType("*[]string")).Return(&keys, nil)
+	imageRepo.On("List", mock.AnythingOfType("*image.ListQuery"), mock.AnythingOfType("*[]*model.Image")).Return(&images, nil)
+	bucketClient.EXPECT().List(gomock.Any()).DoAndReturn(func(fn func(*bucket.ObjectInfo) error) error {
+		return fn(&bucket.ObjectInfo{Key: "checksum", LastModified: t.old})
+	})
+	bucketClient.EXPECT().Head("checksum").Return(&bucket.ObjectInfo{Key: "checksum", LastModified: time.Now()}, nil)
+
+	report, err := NewReconciliationJob(bucketClient, imageRepo, t.conf).(*reconciliationJob).reconcile(context.Background())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), []string{"checksum"}, report.OrphanObjects)
+	assert.Zero(t.T(), report.DeletedObjects)
+}
+
+func (t *ReconciliationJobTest) TestRunOnceRepoFailed() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindObjectKeys", mock.AnythingOfType("*[]string")).Return(nil, errors.New("database unavailable"))
+
+	err := NewReconciliationJob(t.client, imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.EqualError(t.T(), err, "database unavailable")
+}
+
+func (t *ReconciliationJobTest) TestRunOnceBucketFailed() {
+	t.client.SetGetError(errors.New("bucket unavailable"))
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindObjectKeys", mock.AnythingOfType("*[]string")).Return(&t.keys, nil)
+
+	err := NewReconciliationJob(t.client, imageRepo, t.conf).RunOnce(context.Background())
+
+	assert.ErrorContains(t.T(), err, "bucket unavailable")
+	imageRepo.AssertNotCalled(t.T(), "List", mock.Anything, mock.Anything)
+}
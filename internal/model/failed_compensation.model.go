@@ -0,0 +1,15 @@
+package model
+
+import "github.com/isd-sgcu/johnjud-file/constant"
+
+// FailedCompensation is an object that has to be deleted from the bucket, the compensation job keeps deleting it
+// until it succeeds. It is either an object uploaded for an image that could not be stored and that could not be
+// deleted right away, or an object of a purged image that is written in the transaction that purges the image.
+type FailedCompensation struct {
+	Base
+	ObjectKey string                      `json:"object_key" gorm:"mediumtext"`
+	Checksum  string                      `json:"checksum" gorm:"index;size:64"`
+	Reason    constant.CompensationReason `json:"reason" gorm:"tinytext;default:upload"`
+	Attempts  int                         `json:"attempts"`
+	LastError string                      `json:"last_error" gorm:"mediumtext"`
+}
@@ -2,12 +2,25 @@ package model
 
 import (
 	"github.com/google/uuid"
+	"github.com/isd-sgcu/johnjud-file/constant"
 )
 
 type Image struct {
 	Base
-	PetID     *uuid.UUID `json:"pet_id" gorm:"index:idx_name,unique"`
-	Pet       *Pet       `json:"pet" gorm:"foreignKey:PetID;constraint:OnUpdate:CASCADE;OnDelete:SET NULL;"`
-	ImageUrl  string     `json:"image_url" gorm:"mediumtext"`
-	ObjectKey string     `json:"object_key" gorm:"mediumtext"`
+	PetID          *uuid.UUID           `json:"pet_id" gorm:"index"`
+	ImageUrl       string               `json:"image_url" gorm:"mediumtext"`
+	ObjectKey      string               `json:"object_key" gorm:"mediumtext"`
+	ContentType    string               `json:"content_type" gorm:"tinytext"`
+	Filename       string               `json:"filename" gorm:"tinytext"`
+	Size           int64                `json:"size"`
+	Checksum       string               `json:"checksum" gorm:"index;size:64"`
+	PerceptualHash string               `json:"perceptual_hash" gorm:"size:16"`
+	Width          int                  `json:"width"`
+	Height         int                  `json:"height"`
+	BlurHash       string               `json:"blur_hash" gorm:"tinytext"`
+	DominantColor  string               `json:"dominant_color" gorm:"size:7"`
+	Position       int                  `json:"position"`
+	IsCover        bool                 `json:"is_cover"`
+	Status         constant.ImageStatus `json:"status" gorm:"tinytext;default:ready"`
+	Variants       []*ImageVariant      `json:"variants" gorm:"foreignKey:ImageID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
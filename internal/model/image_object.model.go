@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ImageObject is the reference count of the images that share the content addressed object of a checksum.
+type ImageObject struct {
+	Checksum  string    `json:"checksum" gorm:"primaryKey;size:64"`
+	ObjectKey string    `json:"object_key" gorm:"mediumtext"`
+	RefCount  int       `json:"ref_count"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp;autoCreateTime:nano"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp;autoUpdateTime:nano"`
+}
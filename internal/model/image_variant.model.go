@@ -0,0 +1,12 @@
+package model
+
+import "github.com/google/uuid"
+
+type ImageVariant struct {
+	Base
+	ImageID   uuid.UUID `json:"image_id" gorm:"index"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	ImageUrl  string    `json:"image_url" gorm:"mediumtext"`
+	ObjectKey string    `json:"object_key" gorm:"mediumtext"`
+}
@@ -19,4 +19,5 @@ type Pet struct {
 	Background   string          `json:"background" gorm:"tinytext"`
 	Address      string          `json:"address" gorm:"tinytext"`
 	Contact      string          `json:"contact" gorm:"tinytext"`
+	Images       []*Image        `json:"images" gorm:"foreignKey:PetID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
@@ -1,9 +1,13 @@
 package image
 
 import (
+	"time"
+
+	"github.com/isd-sgcu/johnjud-file/constant"
 	"github.com/isd-sgcu/johnjud-file/internal/model"
 	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repositoryImpl struct {
@@ -14,22 +18,516 @@ func NewRepository(db *gorm.DB) image.Repository {
 	return &repositoryImpl{db: db}
 }
 
+func (r *repositoryImpl) WithTx(fn func(repository image.Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&repositoryImpl{db: tx})
+	})
+}
+
 func (r *repositoryImpl) FindOne(id string, result *model.Image) error {
-	return r.db.Model(&model.Image{}).First(result, "id = ?", id).Error
+	return r.db.Model(&model.Image{}).Preload("Variants", orderByWidth).First(result, "id = ?", id).Error
 }
 
 func (r *repositoryImpl) FindByPetId(id string, result *[]*model.Image) error {
-	return r.db.Model(&model.Image{}).Find(&result, "pet_id = ?", id).Error
+	return r.db.Model(&model.Image{}).Preload("Variants", orderByWidth).Scopes(orderByPosition).Find(result, "pet_id = ? AND status = ?", id, constant.ReadyImageStatus).Error
+}
+
+func (r *repositoryImpl) FindByIds(ids []string, result *[]*model.Image) error {
+	return r.db.Model(&model.Image{}).Preload("Variants", orderByWidth).Find(result, "id IN ?", ids).Error
+}
+
+func (r *repositoryImpl) List(query *image.ListQuery, result *[]*model.Image) error {
+	db := filter(r.db.Model(&model.Image{}), &query.Filter)
+
+	order, compare := "DESC", "<"
+	if query.Ascending {
+		order, compare = "ASC", ">"
+	}
+	if query.After != nil {
+		db = db.Where("(created_at, id) "+compare+" (?, ?)", query.After.CreatedAt, query.After.ID)
+	}
+
+	return db.Preload("Variants", orderByWidth).Order("created_at " + order).Order("id " + order).Limit(query.Limit).Find(result).Error
+}
+
+func (r *repositoryImpl) Count(in *image.Filter, result *int64) error {
+	return filter(r.db.Model(&model.Image{}), in).Count(result).Error
 }
 
+func (r *repositoryImpl) FindByChecksum(checksum string, result *model.Image) error {
+	return r.db.Model(&model.Image{}).Preload("Variants", orderByWidth).First(result, "checksum = ? AND status = ?", checksum, constant.ReadyImageStatus).Error
+}
+
+// ReserveObject counts a reference to the object of the checksum before it is uploaded, so that the jobs leave
+// the object alone while it is uploaded and stored. The image created with the checksum takes the reference
+// over, ReleaseObject gives it back when the image cannot be stored.
+func (r *repositoryImpl) ReserveObject(checksum string, objectKey string) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "checksum"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"ref_count": gorm.Expr("image_objects.ref_count + 1"), "updated_at": time.Now()}),
+	}).Create(&model.ImageObject{Checksum: checksum, ObjectKey: objectKey, RefCount: 1}).Error
+}
+
+// ReleaseObject gives back the reference of ReserveObject. The object is left for DeleteUnreferencedObject
+// when it was the last reference.
+func (r *repositoryImpl) ReleaseObject(checksum string) error {
+	return r.db.Model(&model.ImageObject{}).Where("checksum = ? AND ref_count > 0", checksum).Update("ref_count", gorm.Expr("ref_count - 1")).Error
+}
+
+// DeleteUnreferencedObject runs fn, which deletes the objects of the checksum from the bucket, unless an image or
+// an upload holds a reference to them, it is true then. The row of the checksum is created when it is missing and
+// locked until fn returns, so an upload of the same file waits in ReserveObject until the objects are deleted
+// instead of having them deleted under its image.
+func (r *repositoryImpl) DeleteUnreferencedObject(checksum string, fn func() error) (bool, error) {
+	referenced := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ImageObject{Checksum: checksum}).Error
+		if err != nil {
+			return err
+		}
+
+		var object model.ImageObject
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&object, "checksum = ?", checksum).Error
+		if err != nil {
+			return err
+		}
+		if object.RefCount > 0 {
+			referenced = true
+			return nil
+		}
+
+		err = fn()
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("checksum = ?", checksum).Delete(&model.ImageObject{}).Error
+	})
+
+	return referenced, err
+}
+
+// Create stores the image, the reference to the object of its checksum was counted by ReserveObject.
 func (r *repositoryImpl) Create(in *model.Image) error {
 	return r.db.Create(&in).Error
 }
 
+// FindObjectKeys finds the keys of every object the db knows of, which are the objects of the images including
+// the deleted ones that were not purged yet and the objects that are waiting to be deleted by a job.
+func (r *repositoryImpl) FindObjectKeys(result *[]string) error {
+	for _, in := range []interface{}{&model.Image{}, &model.ImageVariant{}, &model.FailedCompensation{}} {
+		var keys []string
+		err := r.db.Unscoped().Model(in).Distinct().Pluck("object_key", &keys).Error
+		if err != nil {
+			return err
+		}
+		*result = append(*result, keys...)
+	}
+
+	return nil
+}
+
+// AssignPet attaches the images to the pet. The images that come from another pet or from none lose their
+// position and cover like in MovePet, the images already on the pet are left as they are.
+// Nothing changes when one of the images does not exist.
+func (r *repositoryImpl) AssignPet(petId string, ids []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := lockImages(tx.Where("id IN ?", ids), len(ids))
+		if err != nil {
+			return err
+		}
+
+		var moved int64
+		err = tx.Model(&model.Image{}).Where("id IN ? AND (pet_id IS NULL OR pet_id <> ?)", ids, petId).Count(&moved).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.Image{}).Where("id IN ? AND (pet_id IS NULL OR pet_id <> ?)", ids, petId).Updates(detachedImage(petId))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != moved {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+}
+
+// UnassignPet detaches the images from their pets, nothing changes when one of them does not exist.
+func (r *repositoryImpl) UnassignPet(ids []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := lockImages(tx.Where("id IN ?", ids), len(ids))
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Image{}).Where("id IN ?", ids).Updates(detachedImage(nil)).Error
+	})
+}
+
+// MovePet moves the images from one pet to another, every image of the pet is moved when no ids are given.
+// Nothing changes when one of the images does not exist or belongs to another pet.
+func (r *repositoryImpl) MovePet(fromPetId string, toPetId string, ids []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		db := tx.Where("pet_id = ?", fromPetId)
+		if len(ids) > 0 {
+			err := lockImages(tx.Where("id IN ? AND pet_id = ?", ids, fromPetId), len(ids))
+			if err != nil {
+				return err
+			}
+
+			db = db.Where("id IN ?", ids)
+		}
+
+		return db.Model(&model.Image{}).Updates(detachedImage(toPetId)).Error
+	})
+}
+
+// Reorder gives the images of the pet the positions of their ids, all of them are moved or none. The images of
+// the pet are locked first, so an image cannot be added to the pet while the ids are checked against them.
+func (r *repositoryImpl) Reorder(petId string, ids []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		images, err := lockPetImages(tx, petId)
+		if err != nil {
+			return err
+		}
+
+		// a partial order would leave the other images at their old positions in between
+		remaining := make(map[string]bool, len(images))
+		for _, locked := range images {
+			remaining[locked.ID.String()] = true
+		}
+		for _, id := range ids {
+			if !remaining[id] {
+				return image.ErrReorderMismatch
+			}
+			delete(remaining, id)
+		}
+		if len(remaining) > 0 {
+			return image.ErrReorderMismatch
+		}
+
+		for i, id := range ids {
+			result := tx.Model(&model.Image{}).Where("id = ? AND pet_id = ?", id, petId).Update("position", i+1)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+
+		return nil
+	})
+}
+
+// SetCover makes the image the only cover image of the pet. The images of the pet are locked first, so two
+// covers set at the same time cannot both be kept.
+func (r *repositoryImpl) SetCover(petId string, id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		images, err := lockPetImages(tx, petId)
+		if err != nil {
+			return err
+		}
+		if !containsImage(images, id) {
+			return gorm.ErrRecordNotFound
+		}
+
+		err = tx.Model(&model.Image{}).Where("pet_id = ? AND is_cover", petId).Update("is_cover", false).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Image{}).Where("id = ?", id).Update("is_cover", true).Error
+	})
+}
+
 func (r *repositoryImpl) Update(id string, in *model.Image) error {
-	return r.db.Where(id, "id = ?", id).Updates(&in).First(&in, "id = ?", id).Error
+	return r.db.Model(&model.Image{}).Where("id = ?", id).Updates(in).First(in, "id = ?", id).Error
 }
 
+// ConfirmUpload makes the pending image ready with the stored object and the derived fields of in, and creates
+// its variants. The row is locked first, so the upload is confirmed once, and it is not found when the image is
+// not pending anymore. The image is read back into in.
+func (r *repositoryImpl) ConfirmUpload(id string, in *model.Image) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var image model.Image
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&image, "id = ? AND status = ?", id, constant.PendingImageStatus).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.Image{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"image_url":       in.ImageUrl,
+			"object_key":      in.ObjectKey,
+			"content_type":    in.ContentType,
+			"size":            in.Size,
+			"checksum":        in.Checksum,
+			"perceptual_hash": in.PerceptualHash,
+			"width":           in.Width,
+			"height":          in.Height,
+			"blur_hash":       in.BlurHash,
+			"dominant_color":  in.DominantColor,
+			"status":          constant.ReadyImageStatus,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(in.Variants) > 0 {
+			for _, variant := range in.Variants {
+				variant.ImageID = image.ID
+			}
+
+			err = tx.Create(&in.Variants).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Preload("Variants", orderByWidth).First(in, "id = ?", id).Error
+	})
+}
+
+// Delete soft deletes the image with its variants. The image keeps its reference to the object of
+// its checksum until it is purged, so that it can be restored until then.
 func (r *repositoryImpl) Delete(id string) error {
-	return r.db.Where("id = ?", id).Delete(&model.Image{}).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var image model.Image
+		err := tx.Select("id").First(&image, "id = ?", id).Error
+		if err != nil {
+			return err
+		}
+
+		return deleteImage(tx, id)
+	})
+}
+
+// DeleteUnassigned deletes the image like Delete, but only while it is not assigned to a pet and was last
+// updated before the given time. The row is locked first so that it cannot be assigned while it is deleted.
+func (r *repositoryImpl) DeleteUnassigned(id string, before time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var image model.Image
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&image, "id = ? AND pet_id IS NULL AND updated_at < ?", id, before).Error
+		if err != nil {
+			return err
+		}
+
+		return deleteImage(tx, id)
+	})
+}
+
+// DeleteExpiredUpload deletes the image like Delete, but only while it is still pending and was created before
+// the given time, after which its upload url has expired. The purge job deletes the uploaded object later.
+func (r *repositoryImpl) DeleteExpiredUpload(id string, before time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var image model.Image
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&image, "id = ? AND status = ? AND created_at < ?", id, constant.PendingImageStatus, before).Error
+		if err != nil {
+			return err
+		}
+
+		return deleteImage(tx, id)
+	})
+}
+
+func deleteImage(tx *gorm.DB, id string) error {
+	err := tx.Where("image_id = ?", id).Delete(&model.ImageVariant{}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Where("id = ?", id).Delete(&model.Image{}).Error
+}
+
+// Restore undeletes the image with its variants. It stops being the cover when its pet was given
+// another cover while it was deleted.
+func (r *repositoryImpl) Restore(id string, result *model.Image) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var image model.Image
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&image, "id = ? AND deleted_at IS NOT NULL", id).Error
+		if err != nil {
+			return err
+		}
+
+		restored := map[string]interface{}{"deleted_at": nil}
+		if image.IsCover && image.PetID != nil {
+			var covers int64
+			err = tx.Model(&model.Image{}).Where("pet_id = ? AND is_cover", image.PetID).Count(&covers).Error
+			if err != nil {
+				return err
+			}
+			if covers > 0 {
+				restored["is_cover"] = false
+			}
+		}
+
+		err = tx.Unscoped().Model(&model.ImageVariant{}).Where("image_id = ?", id).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Model(&model.Image{}).Where("id = ?", id).Updates(restored).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Preload("Variants", orderByWidth).First(result, "id = ?", id).Error
+	})
+}
+
+// FindDeleted finds the images deleted before the given time, the ones deleted first come first.
+func (r *repositoryImpl) FindDeleted(before time.Time, limit int, result *[]*model.Image) error {
+	return r.db.Unscoped().Select("id", "deleted_at").Where("deleted_at < ?", before).Order("deleted_at").Limit(limit).Find(result).Error
+}
+
+// Purge permanently deletes an image that was deleted before the given time and releases its reference to the
+// object of its checksum. The objects are queued as failed compensations in the same transaction when the last
+// reference is released, so that a row never points at an object that was already deleted from the bucket.
+func (r *repositoryImpl) Purge(id string, before time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var image model.Image
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&image, "id = ? AND deleted_at < ?", id, before).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Where("image_id = ?", id).Find(&image.Variants).Error
+		if err != nil {
+			return err
+		}
+
+		err = deleteImage(tx.Unscoped().Session(&gorm.Session{}), id)
+		if err != nil {
+			return err
+		}
+
+		if image.Checksum != "" {
+			var object model.ImageObject
+			err = tx.Clauses(clause.Returning{}).Model(&object).Where("checksum = ?", image.Checksum).Update("ref_count", gorm.Expr("ref_count - 1")).Error
+			if err != nil {
+				return err
+			}
+
+			// other images still use the objects
+			if object.RefCount > 0 {
+				return nil
+			}
+
+			err = tx.Where("checksum = ?", image.Checksum).Delete(&model.ImageObject{}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		compensations := []*model.FailedCompensation{{ObjectKey: image.ObjectKey, Checksum: image.Checksum, Reason: constant.PurgeCompensationReason}}
+		for _, variant := range image.Variants {
+			compensations = append(compensations, &model.FailedCompensation{ObjectKey: variant.ObjectKey, Checksum: image.Checksum, Reason: constant.PurgeCompensationReason})
+		}
+
+		return tx.Create(&compensations).Error
+	})
+}
+
+func filter(db *gorm.DB, in *image.Filter) *gorm.DB {
+	if in.PetID != nil {
+		db = db.Where("pet_id = ?", in.PetID)
+	}
+	if in.Assigned != nil && *in.Assigned {
+		db = db.Where("pet_id IS NOT NULL")
+	}
+	if in.Assigned != nil && !*in.Assigned {
+		db = db.Where("pet_id IS NULL")
+	}
+	if in.Status != "" {
+		db = db.Where("status = ?", in.Status)
+	}
+	if in.ContentType != "" {
+		db = db.Where("content_type = ?", in.ContentType)
+	}
+	if !in.CreatedAfter.IsZero() {
+		db = db.Where("created_at >= ?", in.CreatedAfter)
+	}
+	if !in.CreatedBefore.IsZero() {
+		db = db.Where("created_at < ?", in.CreatedBefore)
+	}
+	if !in.UpdatedBefore.IsZero() {
+		db = db.Where("updated_at < ?", in.UpdatedBefore)
+	}
+	if in.MinSize > 0 {
+		db = db.Where("size >= ?", in.MinSize)
+	}
+	if in.MaxSize > 0 {
+		db = db.Where("size <= ?", in.MaxSize)
+	}
+
+	return db
+}
+
+func (r *repositoryImpl) CreateFailedCompensations(in []*model.FailedCompensation) error {
+	return r.db.Create(&in).Error
+}
+
+// FindFailedCompensations finds the compensations that were retried the longest time ago first.
+func (r *repositoryImpl) FindFailedCompensations(limit int, result *[]*model.FailedCompensation) error {
+	return r.db.Order("updated_at").Limit(limit).Find(result).Error
+}
+
+func (r *repositoryImpl) UpdateFailedCompensation(id string, in *model.FailedCompensation) error {
+	return r.db.Model(&model.FailedCompensation{}).Where("id = ?", id).Updates(in).Error
+}
+
+func (r *repositoryImpl) DeleteFailedCompensation(id string) error {
+	return r.db.Unscoped().Where("id = ?", id).Delete(&model.FailedCompensation{}).Error
+}
+
+// lockImages locks the images of the query until the end of the transaction and fails when there are less of
+// them than expected.
+func lockImages(db *gorm.DB, expected int) error {
+	var images []*model.Image
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Find(&images).Error
+	if err != nil {
+		return err
+	}
+	if len(images) != expected {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// lockPetImages locks the images of the pet and returns their ids.
+func lockPetImages(db *gorm.DB, petId string) ([]*model.Image, error) {
+	var images []*model.Image
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("pet_id = ?", petId).Find(&images).Error
+
+	return images, err
+}
+
+func containsImage(images []*model.Image, id string) bool {
+	for _, locked := range images {
+		if locked.ID.String() == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detachedImage are the columns of an image that is moved to the pet, it leaves the gallery order and the
+// cover of its old pet behind.
+func detachedImage(petId interface{}) map[string]interface{} {
+	return map[string]interface{}{"pet_id": petId, "position": 0, "is_cover": false}
+}
+
+// orderByPosition puts the images that were reordered first, position 0 is an image that was added to the
+// pet after the last reorder and those follow in the order they were created.
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position = 0").Order("position").Order("created_at")
+}
+
+func orderByWidth(db *gorm.DB) *gorm.DB {
+	return db.Order("width")
 }
@@ -0,0 +1,629 @@
+package image
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/isd-sgcu/johnjud-file/constant"
+	"github.com/isd-sgcu/johnjud-file/internal/model"
+	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// RepositoryTest checks the statements the repository sends to postgres. gorm runs in dry run mode on a
+// connection that is never used, so the statements are only built and recorded together with the transactions.
+// The methods that read rows before they write run on sqlmock instead, see mockDB.
+type RepositoryTest struct {
+	suite.Suite
+	db         *gorm.DB
+	statements *[]string
+}
+
+func TestRepository(t *testing.T) {
+	suite.Run(t, new(RepositoryTest))
+}
+
+// timestamps are replaced in the recorded statements, they are the time the statement was built.
+var timestamps = regexp.MustCompile(`'\d{4}-\d{2}-\d{2} [^']*'`)
+
+func (t *RepositoryTest) SetupTest() {
+	t.statements = &[]string{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: &unusedConn{unusedPool{statements: t.statements}}}), &gorm.Config{DryRun: true})
+	assert.Nil(t.T(), err)
+
+	record := func(tx *gorm.DB) {
+		statement := tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+		*t.statements = append(*t.statements, timestamps.ReplaceAllString(statement, "'<now>'"))
+	}
+	assert.Nil(t.T(), db.Callback().Query().After("gorm:query").Register("record", record))
+	assert.Nil(t.T(), db.Callback().Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("record", record))
+	assert.Nil(t.T(), db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("record", record))
+	assert.Nil(t.T(), db.Callback().Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("record", record))
+	t.db = db
+}
+
+func (t *RepositoryTest) TestAssignPetResetsGalleryFields() {
+	petId := uuid.New().String()
+	ids := []string{uuid.New().String(), uuid.New().String()}
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE id IN ($1,$2) AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(ids[0], ids[1]).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]).AddRow(ids[1]))
+	mock.ExpectQuery(`SELECT count(*) FROM "images" WHERE (id IN ($1,$2) AND (pet_id IS NULL OR pet_id <> $3)) AND "images"."deleted_at" IS NULL`).
+		WithArgs(ids[0], ids[1], petId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`UPDATE "images" SET "is_cover"=$1,"pet_id"=$2,"position"=$3,"updated_at"=$4 `+
+		`WHERE (id IN ($5,$6) AND (pet_id IS NULL OR pet_id <> $7)) AND "images"."deleted_at" IS NULL`).
+		WithArgs(false, petId, 0, sqlmock.AnyArg(), ids[0], ids[1], petId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := NewRepository(db).AssignPet(petId, ids)
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestAssignPetMissingImage() {
+	petId := uuid.New().String()
+	ids := []string{uuid.New().String(), uuid.New().String()}
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE id IN ($1,$2) AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(ids[0], ids[1]).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]))
+	mock.ExpectRollback()
+
+	err := NewRepository(db).AssignPet(petId, ids)
+
+	assert.Equal(t.T(), gorm.ErrRecordNotFound, err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestAssignPetNotUpdated() {
+	petId := uuid.New().String()
+	ids := []string{uuid.New().String()}
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE id IN ($1) AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(ids[0]).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]))
+	mock.ExpectQuery(`SELECT count(*) FROM "images" WHERE (id IN ($1) AND (pet_id IS NULL OR pet_id <> $2)) AND "images"."deleted_at" IS NULL`).
+		WithArgs(ids[0], petId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`UPDATE "images" SET "is_cover"=$1,"pet_id"=$2,"position"=$3,"updated_at"=$4 `+
+		`WHERE (id IN ($5) AND (pet_id IS NULL OR pet_id <> $6)) AND "images"."deleted_at" IS NULL`).
+		WithArgs(false, petId, 0, sqlmock.AnyArg(), ids[0], petId).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := NewRepository(db).AssignPet(petId, ids)
+
+	assert.Equal(t.T(), gorm.ErrRecordNotFound, err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestListFiltersAfterCursor() {
+	petId, id := uuid.New(), uuid.New()
+	assigned := true
+	after := &image.Cursor{CreatedAt: time.Now(), ID: uuid.New()}
+	createdAfter, createdBefore := after.CreatedAt.Add(-time.Hour), after.CreatedAt.Add(time.Hour)
+	db, mock := t.mockDB()
+
+	mock.ExpectQuery(`SELECT * FROM "images" WHERE pet_id = $1 AND pet_id IS NOT NULL AND status = $2 AND content_type = $3 `+
+		`AND created_at >= $4 AND created_at < $5 AND size >= $6 AND size <= $7 AND (created_at, id) < ($8, $9) `+
+		`AND "images"."deleted_at" IS NULL ORDER BY created_at DESC,id DESC LIMIT 20`).
+		WithArgs(petId, constant.ReadyImageStatus, "image/png", createdAfter, createdBefore, 1, 1024, after.CreatedAt, after.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "pet_id"}).AddRow(id, petId))
+	mock.ExpectQuery(`SELECT * FROM "image_variants" WHERE "image_variants"."image_id" = $1 AND "image_variants"."deleted_at" IS NULL ORDER BY width`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "image_id", "width"}).AddRow(uuid.New(), id, 160))
+
+	var images []*model.Image
+	err := NewRepository(db).List(&image.ListQuery{
+		Filter: image.Filter{
+			PetID:         &petId,
+			Assigned:      &assigned,
+			Status:        constant.ReadyImageStatus,
+			ContentType:   "image/png",
+			CreatedAfter:  createdAfter,
+			CreatedBefore: createdBefore,
+			MinSize:       1,
+			MaxSize:       1024,
+		},
+		After: after,
+		Limit: 20,
+	}, &images)
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), images, 1)
+	assert.Len(t.T(), images[0].Variants, 1)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestListAscending() {
+	assigned := false
+	after := &image.Cursor{CreatedAt: time.Now(), ID: uuid.New()}
+	db, mock := t.mockDB()
+
+	mock.ExpectQuery(`SELECT * FROM "images" WHERE pet_id IS NULL AND (created_at, id) > ($1, $2) `+
+		`AND "images"."deleted_at" IS NULL ORDER BY created_at ASC,id ASC LIMIT 10`).
+		WithArgs(after.CreatedAt, after.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var images []*model.Image
+	err := NewRepository(db).List(&image.ListQuery{
+		Filter:    image.Filter{Assigned: &assigned},
+		After:     after,
+		Ascending: true,
+		Limit:     10,
+	}, &images)
+
+	assert.Nil(t.T(), err)
+	assert.Empty(t.T(), images)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestListFirstPage() {
+	db, mock := t.mockDB()
+
+	mock.ExpectQuery(`SELECT * FROM "images" WHERE "images"."deleted_at" IS NULL ORDER BY created_at DESC,id DESC LIMIT 10`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var images []*model.Image
+	err := NewRepository(db).List(&image.ListQuery{Limit: 10}, &images)
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestUnassignPetLocksImages() {
+	ids := []string{uuid.New().String(), uuid.New().String()}
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE id IN ($1,$2) AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(ids[0], ids[1]).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]).AddRow(ids[1]))
+	mock.ExpectExec(`UPDATE "images" SET "is_cover"=$1,"pet_id"=$2,"position"=$3,"updated_at"=$4 WHERE id IN ($5,$6) AND "images"."deleted_at" IS NULL`).
+		WithArgs(false, nil, 0, sqlmock.AnyArg(), ids[0], ids[1]).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := NewRepository(db).UnassignPet(ids)
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestUnassignPetMissingImage() {
+	ids := []string{uuid.New().String(), uuid.New().String()}
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE id IN ($1,$2) AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(ids[0], ids[1]).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[1]))
+	mock.ExpectRollback()
+
+	err := NewRepository(db).UnassignPet(ids)
+
+	assert.Equal(t.T(), gorm.ErrRecordNotFound, err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestMovePetLocksImages() {
+	fromPetId, toPetId := uuid.New().String(), uuid.New().String()
+	ids := []string{uuid.New().String(), uuid.New().String()}
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE (id IN ($1,$2) AND pet_id = $3) AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(ids[0], ids[1], fromPetId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]).AddRow(ids[1]))
+	mock.ExpectExec(`UPDATE "images" SET "is_cover"=$1,"pet_id"=$2,"position"=$3,"updated_at"=$4 WHERE pet_id = $5 AND id IN ($6,$7) AND "images"."deleted_at" IS NULL`).
+		WithArgs(false, toPetId, 0, sqlmock.AnyArg(), fromPetId, ids[0], ids[1]).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := NewRepository(db).MovePet(fromPetId, toPetId, ids)
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestMovePetImageOfAnotherPet() {
+	fromPetId, toPetId := uuid.New().String(), uuid.New().String()
+	ids := []string{uuid.New().String(), uuid.New().String()}
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE (id IN ($1,$2) AND pet_id = $3) AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(ids[0], ids[1], fromPetId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]))
+	mock.ExpectRollback()
+
+	err := NewRepository(db).MovePet(fromPetId, toPetId, ids)
+
+	assert.Equal(t.T(), gorm.ErrRecordNotFound, err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestMovePetAllImages() {
+	fromPetId, toPetId := uuid.New().String(), uuid.New().String()
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "images" SET "is_cover"=$1,"pet_id"=$2,"position"=$3,"updated_at"=$4 WHERE pet_id = $5 AND "images"."deleted_at" IS NULL`).
+		WithArgs(false, toPetId, 0, sqlmock.AnyArg(), fromPetId).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	err := NewRepository(db).MovePet(fromPetId, toPetId, nil)
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestReorderLocksPetImages() {
+	petId := uuid.New().String()
+	ids := []string{uuid.New().String(), uuid.New().String()}
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE pet_id = $1 AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(petId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[1]).AddRow(ids[0]))
+	for i, id := range ids {
+		mock.ExpectExec(`UPDATE "images" SET "position"=$1,"updated_at"=$2 WHERE (id = $3 AND pet_id = $4) AND "images"."deleted_at" IS NULL`).
+			WithArgs(i+1, sqlmock.AnyArg(), id, petId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	err := NewRepository(db).Reorder(petId, ids)
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestReorderIdsMismatch() {
+	petId := uuid.New().String()
+	images := []string{uuid.New().String(), uuid.New().String()}
+
+	tests := []struct {
+		name string
+		ids  []string
+	}{
+		{name: "missing image", ids: []string{images[0]}},
+		{name: "duplicate image", ids: []string{images[0], images[0]}},
+		{name: "image of another pet", ids: []string{images[0], images[1], uuid.New().String()}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			db, mock := t.mockDB()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT "id" FROM "images" WHERE pet_id = $1 AND "images"."deleted_at" IS NULL FOR UPDATE`).
+				WithArgs(petId).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(images[0]).AddRow(images[1]))
+			mock.ExpectRollback()
+
+			err := NewRepository(db).Reorder(petId, test.ids)
+
+			assert.Equal(t.T(), image.ErrReorderMismatch, err)
+			assert.Nil(t.T(), mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func (t *RepositoryTest) TestSetCoverLocksPetImages() {
+	petId := uuid.New().String()
+	id := uuid.New().String()
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE pet_id = $1 AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(petId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New().String()).AddRow(id))
+	mock.ExpectExec(`UPDATE "images" SET "is_cover"=$1,"updated_at"=$2 WHERE (pet_id = $3 AND is_cover) AND "images"."deleted_at" IS NULL`).
+		WithArgs(false, sqlmock.AnyArg(), petId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "images" SET "is_cover"=$1,"updated_at"=$2 WHERE id = $3 AND "images"."deleted_at" IS NULL`).
+		WithArgs(true, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := NewRepository(db).SetCover(petId, id)
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestSetCoverImageOfAnotherPet() {
+	petId := uuid.New().String()
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "images" WHERE pet_id = $1 AND "images"."deleted_at" IS NULL FOR UPDATE`).
+		WithArgs(petId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New().String()))
+	mock.ExpectRollback()
+
+	err := NewRepository(db).SetCover(petId, uuid.New().String())
+
+	assert.Equal(t.T(), gorm.ErrRecordNotFound, err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestRestoreCover() {
+	tests := []struct {
+		name   string
+		covers int
+		sql    string
+	}{
+		{
+			name:   "stays the cover",
+			covers: 0,
+			sql:    `UPDATE "images" SET "deleted_at"=$1,"updated_at"=$2 WHERE id = $3`,
+		},
+		{
+			name:   "pet has another cover",
+			covers: 1,
+			sql:    `UPDATE "images" SET "deleted_at"=$1,"is_cover"=$2,"updated_at"=$3 WHERE id = $4`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			id, petId := uuid.New().String(), uuid.New().String()
+			db, mock := t.mockDB()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT * FROM "images" WHERE id = $1 AND deleted_at IS NOT NULL ORDER BY "images"."id" LIMIT 1 FOR UPDATE`).
+				WithArgs(id).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "pet_id", "is_cover", "deleted_at"}).AddRow(id, petId, true, time.Now()))
+			mock.ExpectQuery(`SELECT count(*) FROM "images" WHERE (pet_id = $1 AND is_cover) AND "images"."deleted_at" IS NULL`).
+				WithArgs(petId).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(test.covers))
+			mock.ExpectExec(`UPDATE "image_variants" SET "deleted_at"=$1,"updated_at"=$2 WHERE image_id = $3`).
+				WithArgs(nil, sqlmock.AnyArg(), id).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			args := []driver.Value{nil, sqlmock.AnyArg(), id}
+			if test.covers > 0 {
+				args = []driver.Value{nil, false, sqlmock.AnyArg(), id}
+			}
+			mock.ExpectExec(test.sql).
+				WithArgs(args...).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(`SELECT * FROM "images" WHERE id = $1 AND "images"."deleted_at" IS NULL ORDER BY "images"."id" LIMIT 1`).
+				WithArgs(id).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "pet_id", "is_cover"}).AddRow(id, petId, test.covers == 0))
+			mock.ExpectQuery(`SELECT * FROM "image_variants" WHERE "image_variants"."image_id" = $1 AND "image_variants"."deleted_at" IS NULL ORDER BY width`).
+				WithArgs(id).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "image_id"}))
+			mock.ExpectCommit()
+
+			var image model.Image
+			err := NewRepository(db).Restore(id, &image)
+
+			assert.Nil(t.T(), err)
+			assert.Equal(t.T(), test.covers == 0, image.IsCover)
+			assert.Nil(t.T(), mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func (t *RepositoryTest) TestRestoreNotDeleted() {
+	id := uuid.New().String()
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT * FROM "images" WHERE id = $1 AND deleted_at IS NOT NULL ORDER BY "images"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	var image model.Image
+	err := NewRepository(db).Restore(id, &image)
+
+	assert.Equal(t.T(), gorm.ErrRecordNotFound, err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestPurgeQueuesObjectsOfLastReference() {
+	id, variantId := uuid.New().String(), uuid.New().String()
+	before := time.Now()
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	t.expectPurgedImage(mock, id, variantId, before)
+	mock.ExpectQuery(`UPDATE "image_objects" SET "ref_count"=ref_count - 1,"updated_at"=$1 WHERE checksum = $2 RETURNING *`).
+		WithArgs(sqlmock.AnyArg(), "checksum").
+		WillReturnRows(sqlmock.NewRows([]string{"checksum", "object_key", "ref_count"}).AddRow("checksum", "checksum", 0))
+	mock.ExpectExec(`DELETE FROM "image_objects" WHERE checksum = $1`).
+		WithArgs("checksum").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO "failed_compensations" ("id","created_at","updated_at","deleted_at","object_key","checksum","reason","attempts","last_error") `+
+		`VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9),($10,$11,$12,$13,$14,$15,$16,$17,$18)`).
+		WithArgs(
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "checksum", "checksum", constant.PurgeCompensationReason, 0, "",
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "checksum_480w", "checksum", constant.PurgeCompensationReason, 0, "",
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := NewRepository(db).Purge(id, before)
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestPurgeKeepsObjectsOfOtherImages() {
+	id, variantId := uuid.New().String(), uuid.New().String()
+	before := time.Now()
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	t.expectPurgedImage(mock, id, variantId, before)
+	mock.ExpectQuery(`UPDATE "image_objects" SET "ref_count"=ref_count - 1,"updated_at"=$1 WHERE checksum = $2 RETURNING *`).
+		WithArgs(sqlmock.AnyArg(), "checksum").
+		WillReturnRows(sqlmock.NewRows([]string{"checksum", "object_key", "ref_count"}).AddRow("checksum", "checksum", 1))
+	mock.ExpectCommit()
+
+	err := NewRepository(db).Purge(id, before)
+
+	assert.Nil(t.T(), err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+func (t *RepositoryTest) TestPurgeNotDeleted() {
+	id := uuid.New().String()
+	before := time.Now()
+	db, mock := t.mockDB()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT * FROM "images" WHERE id = $1 AND deleted_at < $2 ORDER BY "images"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs(id, before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err := NewRepository(db).Purge(id, before)
+
+	assert.Equal(t.T(), gorm.ErrRecordNotFound, err)
+	assert.Nil(t.T(), mock.ExpectationsWereMet())
+}
+
+// expectPurgedImage expects the image with its variant to be locked and deleted permanently.
+func (t *RepositoryTest) expectPurgedImage(mock sqlmock.Sqlmock, id string, variantId string, before time.Time) {
+	mock.ExpectQuery(`SELECT * FROM "images" WHERE id = $1 AND deleted_at < $2 ORDER BY "images"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs(id, before).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "object_key", "checksum", "deleted_at"}).AddRow(id, "checksum", "checksum", before.Add(-time.Hour)))
+	mock.ExpectQuery(`SELECT * FROM "image_variants" WHERE image_id = $1`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "image_id", "object_key"}).AddRow(variantId, id, "checksum_480w"))
+	mock.ExpectExec(`DELETE FROM "image_variants" WHERE image_id = $1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM "images" WHERE id = $1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func (t *RepositoryTest) TestReserveObjectCountsReference() {
+	err := NewRepository(t.db).ReserveObject("checksum", "checksum")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), []string{
+		"BEGIN",
+		`INSERT INTO "image_objects" ("checksum","object_key","ref_count","created_at","updated_at") VALUES ('checksum','checksum',1,'<now>','<now>') ` +
+			`ON CONFLICT ("checksum") DO UPDATE SET "ref_count"=image_objects.ref_count + 1,"updated_at"='<now>'`,
+		"COMMIT",
+	}, *t.statements)
+}
+
+func (t *RepositoryTest) TestDeleteUnreferencedObjectDeletesUnderLock() {
+	referenced, err := NewRepository(t.db).DeleteUnreferencedObject("checksum", func() error {
+		*t.statements = append(*t.statements, "delete the objects from the bucket")
+		return nil
+	})
+
+	assert.Nil(t.T(), err)
+	assert.False(t.T(), referenced)
+	// the row exists and stays locked from before the objects are deleted until the transaction ends, so the
+	// upsert of ReserveObject for the same checksum waits for the delete
+	assert.Equal(t.T(), []string{
+		"BEGIN",
+		`INSERT INTO "image_objects" ("checksum","object_key","ref_count","created_at","updated_at") VALUES ('checksum','',0,'<now>','<now>') ON CONFLICT DO NOTHING`,
+		`SELECT * FROM "image_objects" WHERE checksum = 'checksum' ORDER BY "image_objects"."checksum" LIMIT 1 FOR UPDATE`,
+		"delete the objects from the bucket",
+		`DELETE FROM "image_objects" WHERE checksum = 'checksum'`,
+		"COMMIT",
+	}, *t.statements)
+}
+
+func (t *RepositoryTest) TestDeleteUnreferencedObjectDeleteFailed() {
+	_, err := NewRepository(t.db).DeleteUnreferencedObject("checksum", func() error {
+		return errors.New("bucket unavailable")
+	})
+
+	assert.EqualError(t.T(), err, "bucket unavailable")
+	assert.Equal(t.T(), "ROLLBACK", (*t.statements)[len(*t.statements)-1])
+	assert.NotContains(t.T(), *t.statements, `DELETE FROM "image_objects" WHERE checksum = 'checksum'`)
+}
+
+// mockDB opens gorm on a sqlmock connection, for the methods that depend on the rows postgres returns. The
+// statements have to match exactly.
+func (t *RepositoryTest) mockDB() (*gorm.DB, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.Nil(t.T(), err)
+	t.T().Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	assert.Nil(t.T(), err)
+
+	return db, mock
+}
+
+// unusedConn is the connection of a dry run, gorm never sends a statement to it. It records when transactions
+// begin and end.
+type unusedConn struct {
+	unusedPool
+}
+
+func (c *unusedConn) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	*c.statements = append(*c.statements, "BEGIN")
+	return &unusedTx{unusedPool: c.unusedPool}, nil
+}
+
+type unusedTx struct {
+	unusedPool
+}
+
+func (tx *unusedTx) Commit() error {
+	*tx.statements = append(*tx.statements, "COMMIT")
+	return nil
+}
+
+func (tx *unusedTx) Rollback() error {
+	*tx.statements = append(*tx.statements, "ROLLBACK")
+	return nil
+}
+
+type unusedPool struct {
+	statements *[]string
+}
+
+var errUnusedConn = errors.New("the connection of a dry run is not used")
+
+func (unusedPool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errUnusedConn
+}
+
+func (unusedPool) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errUnusedConn
+}
+
+func (unusedPool) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errUnusedConn
+}
+
+func (unusedPool) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
@@ -1,11 +1,20 @@
 package image
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
 	"github.com/isd-sgcu/johnjud-file/constant"
 	"github.com/isd-sgcu/johnjud-file/internal/model"
 	"github.com/isd-sgcu/johnjud-file/internal/utils"
@@ -13,23 +22,54 @@ import (
 	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
 	proto "github.com/isd-sgcu/johnjud-go-proto/johnjud/file/image/v1"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
 	"gorm.io/gorm"
 )
 
+// downloadChunkSize is the maximum size of the file chunk in each message of a download stream.
+const downloadChunkSize = 64 * 1024
+
+// imageHeaderSize is the size of the beginning of a streamed upload that is validated before it is stored,
+// it leaves room for the metadata segments that come before the dimensions of a JPEG.
+const imageHeaderSize = 128 * 1024
+
+// stagedKeyPrefix starts the temporary keys that uploads are streamed to before they are stored under their
+// checksum.
+const stagedKeyPrefix = "upload_"
+
+// maxBatchSize is the maximum number of ids in a request for many images.
+const maxBatchSize = 100
+
+// defaultPageSize is the size of a page of the image listing when it is not given, maxPageSize is its maximum.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var (
+	errUploadChunkRequired = errors.New(constant.UploadChunkRequiredErrorMessage)
+	errFileTooLarge        = errors.New(constant.FileTooLargeErrorMessage)
+)
+
 type serviceImpl struct {
 	proto.UnimplementedImageServiceServer
 	client     bucket.Client
 	repository image.Repository
 	random     utils.RandomUtil
+	imageUtil  utils.ImageUtil
+	conf       cfgldr.Image
 }
 
-func NewService(client bucket.Client, repository image.Repository, random utils.RandomUtil) proto.ImageServiceServer {
+func NewService(client bucket.Client, repository image.Repository, random utils.RandomUtil, imageUtil utils.ImageUtil, conf cfgldr.Image) proto.ImageServiceServer {
 	return &serviceImpl{
 		client:     client,
 		repository: repository,
 		random:     random,
+		imageUtil:  imageUtil,
+		conf:       conf,
 	}
 }
 
@@ -53,7 +93,165 @@ func (s *serviceImpl) FindByPetId(_ context.Context, req *proto.FindImageByPetId
 	return &proto.FindImageByPetIdResponse{Images: RawToDtoList(&images)}, nil
 }
 
-func (s *serviceImpl) Upload(_ context.Context, req *proto.UploadImageRequest) (res *proto.UploadImageResponse, err error) {
+func (s *serviceImpl) FindOne(_ context.Context, req *proto.FindOneImageRequest) (res *proto.FindOneImageResponse, err error) {
+	_, err = uuid.Parse(req.Id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "find one").
+			Str("id", req.Id).
+			Msg(constant.ImageIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+	}
+
+	var image model.Image
+
+	err = s.repository.FindOne(req.Id, &image)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "find one").
+			Str("id", req.Id).
+			Msg("Error finding image from repo")
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	return &proto.FindOneImageResponse{Image: RawToDto(&image)}, nil
+}
+
+func (s *serviceImpl) FindByIds(_ context.Context, req *proto.FindImagesByIdsRequest) (res *proto.FindImagesByIdsResponse, err error) {
+	if len(req.Ids) > maxBatchSize {
+		return nil, status.Error(codes.InvalidArgument, constant.TooManyIdsErrorMessage)
+	}
+
+	ids := make([]string, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", "find by ids").
+				Str("id", id).
+				Msg(constant.ImageIdNotUUIDErrorMessage)
+
+			return nil, status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+		}
+		ids = append(ids, parsed.String())
+	}
+
+	if len(ids) == 0 {
+		return &proto.FindImagesByIdsResponse{}, nil
+	}
+
+	var images []*model.Image
+	err = s.repository.FindByIds(ids, &images)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "find by ids").
+			Strs("ids", ids).
+			Msg("Error finding images by ids from repo")
+
+		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	// the repository returns the images in any order and once even if an id is repeated
+	found := make(map[string]*model.Image, len(images))
+	for _, image := range images {
+		found[image.ID.String()] = image
+	}
+
+	ordered := make([]*model.Image, 0, len(images))
+	for _, id := range ids {
+		image, ok := found[id]
+		if ok {
+			ordered = append(ordered, image)
+		}
+	}
+
+	return &proto.FindImagesByIdsResponse{Images: RawToDtoList(&ordered)}, nil
+}
+
+func (s *serviceImpl) List(_ context.Context, req *proto.ListImagesRequest) (res *proto.ListImagesResponse, err error) {
+	if req.PageSize > maxPageSize {
+		return nil, status.Error(codes.InvalidArgument, constant.PageSizeTooLargeErrorMessage)
+	}
+
+	filter, err := toFilter(req.Filter)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "list").
+			Msg(constant.InvalidImageFilterErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.InvalidImageFilterErrorMessage)
+	}
+
+	query := &image.ListQuery{
+		Filter:    *filter,
+		Ascending: req.Order == proto.ListImagesOrder_LIST_IMAGES_ORDER_OLDEST_FIRST,
+		Limit:     defaultPageSize,
+	}
+	if req.PageSize > 0 {
+		query.Limit = int(req.PageSize)
+	}
+	if req.Cursor != "" {
+		query.After, err = decodeCursor(req.Cursor)
+		if err != nil {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", "list").
+				Str("cursor", req.Cursor).
+				Msg(constant.InvalidCursorErrorMessage)
+
+			return nil, status.Error(codes.InvalidArgument, constant.InvalidCursorErrorMessage)
+		}
+	}
+
+	// one more image than the page tells whether there is a next page
+	pageSize := query.Limit
+	query.Limit++
+
+	var images []*model.Image
+	err = s.repository.List(query, &images)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "list").
+			Msg("Error listing images from repo")
+
+		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	res = &proto.ListImagesResponse{}
+	if len(images) > pageSize {
+		images = images[:pageSize]
+		last := images[pageSize-1]
+		res.NextCursor = encodeCursor(&image.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	res.Images = RawToDtoList(&images)
+
+	if req.IncludeTotal {
+		err = s.repository.Count(filter, &res.Total)
+		if err != nil {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", "list").
+				Msg("Error counting images from repo")
+
+			return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *serviceImpl) Upload(ctx context.Context, req *proto.UploadImageRequest) (res *proto.UploadImageResponse, err error) {
 	if req.PetId != "" {
 		_, err = uuid.Parse(req.PetId)
 		if err != nil {
@@ -67,45 +265,107 @@ func (s *serviceImpl) Upload(_ context.Context, req *proto.UploadImageRequest) (
 		}
 	}
 
-	randomString, err := s.random.GenerateRandomString(10)
+	contentType, err := s.validateImage(req.Data, int64(len(req.Data)))
 	if err != nil {
 		log.Error().Err(err).
 			Str("service", "image").
 			Str("module", "upload").
 			Str("petId", req.PetId).
-			Msg("Error while generating random string")
-		return nil, status.Error(codes.Internal, "Error while generating random string")
+			Msg("Invalid image")
+
+		return nil, err
+	}
+
+	staged, err := s.stage(ctx, "upload", bytes.NewReader(req.Data), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, _ := DtoToRaw(&proto.Image{PetId: req.PetId})
+	raw.Filename = req.Filename
+
+	err = s.store(ctx, "upload", raw, staged, s.repository.Create)
+	if err != nil {
+		return nil, err
 	}
 
-	imageUrl, objectKey, err := s.client.Upload(req.Data, req.Filename+"_"+randomString)
+	return &proto.UploadImageResponse{Image: RawToDto(raw)}, nil
+}
+
+func (s *serviceImpl) UploadStream(stream proto.ImageService_UploadStreamServer) error {
+	// the upload to the bucket is cancelled when the client goes away
+	ctx := stream.Context()
+
+	req, err := stream.Recv()
 	if err != nil {
 		log.Error().Err(err).
 			Str("service", "image").
-			Str("module", "upload").
-			Str("petId", req.PetId).
-			Msg(constant.UploadToBucketErrorMessage)
+			Str("module", "upload stream").
+			Msg(constant.ReceiveUploadErrorMessage)
 
-		return nil, status.Error(codes.Internal, constant.UploadToBucketErrorMessage)
+		return status.Error(codes.Unknown, constant.ReceiveUploadErrorMessage)
 	}
 
-	raw, _ := DtoToRaw(&proto.Image{
-		PetId:     req.PetId,
-		ImageUrl:  imageUrl,
-		ObjectKey: objectKey,
-	})
+	metadata := req.GetMetadata()
+	if metadata == nil {
+		return status.Error(codes.InvalidArgument, constant.UploadMetadataRequiredErrorMessage)
+	}
 
-	err = s.repository.Create(raw)
+	if metadata.PetId != "" {
+		_, err = uuid.Parse(metadata.PetId)
+		if err != nil {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", "upload stream").
+				Str("petId", metadata.PetId).
+				Msg(constant.PetIdNotUUIDErrorMessage)
+
+			return status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
+		}
+	}
+
+	// the header is validated before the rest of the file is received
+	reader := &uploadStreamReader{stream: stream, maxSize: s.conf.MaxFileSize}
+	header := make([]byte, imageHeaderSize)
+	n, _ := io.ReadFull(reader, header)
+	header = header[:n]
+	if reader.err != nil && reader.err != io.EOF {
+		return receiveError(reader.err, metadata.PetId)
+	}
+
+	contentType, err := s.validateImage(header, int64(n))
 	if err != nil {
 		log.Error().Err(err).
 			Str("service", "image").
-			Str("module", "upload").
-			Str("petId", req.PetId).
-			Msg(constant.CreateImageErrorMessage)
+			Str("module", "upload stream").
+			Str("petId", metadata.PetId).
+			Msg("Invalid image")
 
-		return nil, status.Error(codes.Internal, constant.CreateImageErrorMessage)
+		return err
 	}
 
-	return &proto.UploadImageResponse{Image: RawToDto(raw)}, nil
+	// the rest of the file is streamed to the bucket as it is received
+	staged, err := s.stage(ctx, "upload stream", io.MultiReader(bytes.NewReader(header), reader), contentType)
+	if reader.err != nil && reader.err != io.EOF {
+		if staged != nil {
+			s.discard("upload stream", staged.key)
+		}
+
+		return receiveError(reader.err, metadata.PetId)
+	}
+	if err != nil {
+		return err
+	}
+
+	raw, _ := DtoToRaw(&proto.Image{PetId: metadata.PetId})
+	raw.Filename = metadata.Filename
+
+	err = s.store(ctx, "upload stream", raw, staged, s.repository.Create)
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&proto.UploadImageResponse{Image: RawToDto(raw)})
 }
 
 func (s *serviceImpl) AssignPet(_ context.Context, req *proto.AssignPetRequest) (res *proto.AssignPetResponse, err error) {
@@ -120,18 +380,26 @@ func (s *serviceImpl) AssignPet(_ context.Context, req *proto.AssignPetRequest)
 		return nil, status.Error(codes.InvalidArgument, constant.PrimaryKeyRequiredErrorMessage)
 	}
 
-	for _, id := range req.Ids {
-		err = s.repository.Update(id, &model.Image{
-			PetID: &petId,
-		})
-		if err == nil {
-			continue
-		}
+	ids, err := parseImageIds("assign pet", req.Ids)
+	if err != nil {
+		return nil, err
+	}
 
+	// the repository locks the images before they are assigned, the missing ids are only looked up for the error
+	var missing []string
+	err = s.repository.AssignPet(petId.String(), ids)
+	if err == gorm.ErrRecordNotFound {
+		var images []*model.Image
+		if findErr := s.repository.FindByIds(ids, &images); findErr == nil {
+			missing = missingIds(ids, images)
+		}
+	}
+	if err != nil {
 		log.Error().Err(err).
 			Str("service", "image").
 			Str("module", "assign pet").
 			Str("petId", req.PetId).
+			Strs("missingIds", missing).
 			Msg("Error updating image in repo")
 
 		if strings.Contains(err.Error(), gorm.ErrForeignKeyViolated.Error()) {
@@ -139,7 +407,7 @@ func (s *serviceImpl) AssignPet(_ context.Context, req *proto.AssignPetRequest)
 		}
 		switch err {
 		case gorm.ErrRecordNotFound:
-			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+			return nil, imagesNotFoundError(missing)
 		default:
 			return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
 		}
@@ -148,108 +416,1474 @@ func (s *serviceImpl) AssignPet(_ context.Context, req *proto.AssignPetRequest)
 	return &proto.AssignPetResponse{Success: true}, nil
 }
 
-func (s *serviceImpl) Delete(_ context.Context, req *proto.DeleteImageRequest) (res *proto.DeleteImageResponse, err error) {
-	var image model.Image
+func (s *serviceImpl) UnassignPet(_ context.Context, req *proto.UnassignPetRequest) (res *proto.UnassignPetResponse, err error) {
+	if len(req.Ids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, constant.ImageIdsRequiredErrorMessage)
+	}
 
-	err = s.repository.FindOne(req.Id, &image)
+	ids, err := parseImageIds("unassign pet", req.Ids)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repository.UnassignPet(ids)
 	if err != nil {
 		log.Error().Err(err).
 			Str("service", "image").
-			Str("module", "delete").
-			Str("id", req.Id).
-			Msg("Error finding image from repo")
+			Str("module", "unassign pet").
+			Strs("ids", ids).
+			Msg("Error unassigning images in repo")
 		if err == gorm.ErrRecordNotFound {
 			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
 		}
 
-		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+		return nil, status.Error(codes.Internal, constant.UpdateImageErrorMessage)
 	}
 
-	err = s.client.Delete(image.ObjectKey)
+	return &proto.UnassignPetResponse{Success: true}, nil
+}
+
+func (s *serviceImpl) MoveImages(_ context.Context, req *proto.MoveImagesRequest) (res *proto.MoveImagesResponse, err error) {
+	fromPetId, err := uuid.Parse(req.FromPetId)
 	if err != nil {
 		log.Error().Err(err).
 			Str("service", "image").
-			Str("module", "delete").
-			Str("id", req.Id).
-			Msg(constant.DeleteFromBucketErrorMessage)
+			Str("module", "move images").
+			Str("petId", req.FromPetId).
+			Msg(constant.PetIdNotUUIDErrorMessage)
 
-		return nil, status.Error(codes.Internal, constant.DeleteFromBucketErrorMessage)
+		return nil, status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
 	}
 
-	err = s.repository.Delete(req.Id)
+	toPetId, err := uuid.Parse(req.ToPetId)
 	if err != nil {
 		log.Error().Err(err).
 			Str("service", "image").
-			Str("module", "delete").
-			Str("id", req.Id).
-			Msg(constant.DeleteImageErrorMessage)
+			Str("module", "move images").
+			Str("petId", req.ToPetId).
+			Msg(constant.PetIdNotUUIDErrorMessage)
 
-		return nil, status.Error(codes.Internal, constant.DeleteImageErrorMessage)
+		return nil, status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
 	}
 
-	return &proto.DeleteImageResponse{Success: true}, nil
-}
-
-func DtoToRaw(in *proto.Image) (result *model.Image, err error) {
-	var id uuid.UUID
-	if in.Id != "" {
-		id, err = uuid.Parse(in.Id)
-		if err != nil {
-			return nil, err
-		}
+	if fromPetId == toPetId {
+		return nil, status.Error(codes.InvalidArgument, constant.SamePetErrorMessage)
 	}
 
-	petId, err := uuid.Parse(in.PetId)
+	ids, err := parseImageIds("move images", req.Ids)
 	if err != nil {
-		return &model.Image{
-			Base: model.Base{
-				ID:        id,
-				CreatedAt: time.Time{},
-				UpdatedAt: time.Time{},
-				DeletedAt: gorm.DeletedAt{},
-			},
-			PetID:     nil,
-			ImageUrl:  in.ImageUrl,
-			ObjectKey: in.ObjectKey,
-		}, nil
+		return nil, err
 	}
 
-	return &model.Image{
-		Base: model.Base{
-			ID:        id,
-			CreatedAt: time.Time{},
-			UpdatedAt: time.Time{},
-			DeletedAt: gorm.DeletedAt{},
-		},
-		PetID:     &petId,
-		ImageUrl:  in.ImageUrl,
-		ObjectKey: in.ObjectKey,
-	}, nil
-}
+	err = s.repository.MovePet(fromPetId.String(), toPetId.String(), ids)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "move images").
+			Str("fromPetId", req.FromPetId).
+			Str("toPetId", req.ToPetId).
+			Msg("Error moving images in repo")
 
-func RawToDtoList(in *[]*model.Image) []*proto.Image {
-	var result []*proto.Image
-	for _, b := range *in {
-		result = append(result, RawToDto(b))
+		if strings.Contains(err.Error(), gorm.ErrForeignKeyViolated.Error()) {
+			return nil, status.Error(codes.NotFound, constant.PetIdNotFoundErrorMessage)
+		}
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.UpdateImageErrorMessage)
 	}
 
-	return result
+	return &proto.MoveImagesResponse{Success: true}, nil
 }
 
-func RawToDto(in *model.Image) *proto.Image {
-	var id string
-	var petId string
-	if in.ID != uuid.Nil {
-		id = in.ID.String()
+func (s *serviceImpl) Reorder(_ context.Context, req *proto.ReorderImagesRequest) (res *proto.ReorderImagesResponse, err error) {
+	_, err = uuid.Parse(req.PetId)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "reorder").
+			Str("petId", req.PetId).
+			Msg(constant.PetIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
 	}
-	if in.PetID != nil {
-		petId = in.PetID.String()
+
+	err = s.repository.Reorder(req.PetId, req.Ids)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "reorder").
+			Str("petId", req.PetId).
+			Msg("Error reordering images in repo")
+		switch err {
+		case image.ErrReorderMismatch:
+			return nil, status.Error(codes.InvalidArgument, constant.ReorderIdsMismatchErrorMessage)
+		case gorm.ErrRecordNotFound:
+			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		default:
+			return nil, status.Error(codes.Internal, constant.UpdateImageErrorMessage)
+		}
 	}
 
-	return &proto.Image{
-		Id:        id,
-		PetId:     petId,
-		ImageUrl:  in.ImageUrl,
-		ObjectKey: in.ObjectKey,
+	images, err := s.findPetImages("reorder", req.PetId)
+	if err != nil {
+		return nil, err
 	}
+
+	return &proto.ReorderImagesResponse{Images: RawToDtoList(&images)}, nil
+}
+
+func (s *serviceImpl) SetCover(_ context.Context, req *proto.SetCoverImageRequest) (res *proto.SetCoverImageResponse, err error) {
+	_, err = uuid.Parse(req.PetId)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "set cover").
+			Str("petId", req.PetId).
+			Msg(constant.PetIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
+	}
+
+	_, err = uuid.Parse(req.Id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "set cover").
+			Str("id", req.Id).
+			Msg(constant.ImageIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+	}
+
+	err = s.repository.SetCover(req.PetId, req.Id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "set cover").
+			Str("petId", req.PetId).
+			Str("id", req.Id).
+			Msg("Error setting cover image in repo")
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.UpdateImageErrorMessage)
+	}
+
+	var image model.Image
+	err = s.repository.FindOne(req.Id, &image)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "set cover").
+			Str("id", req.Id).
+			Msg("Error finding image from repo")
+
+		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	return &proto.SetCoverImageResponse{Image: RawToDto(&image)}, nil
+}
+
+// Delete only soft deletes the image, it can be restored until the purge job removes it and its objects after
+// the retention.
+func (s *serviceImpl) Delete(_ context.Context, req *proto.DeleteImageRequest) (res *proto.DeleteImageResponse, err error) {
+	_, err = uuid.Parse(req.Id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "delete").
+			Str("id", req.Id).
+			Msg(constant.ImageIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+	}
+
+	err = s.repository.Delete(req.Id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "delete").
+			Str("id", req.Id).
+			Msg(constant.DeleteImageErrorMessage)
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.DeleteImageErrorMessage)
+	}
+
+	return &proto.DeleteImageResponse{Success: true}, nil
+}
+
+func (s *serviceImpl) Restore(_ context.Context, req *proto.RestoreImageRequest) (res *proto.RestoreImageResponse, err error) {
+	_, err = uuid.Parse(req.Id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "restore").
+			Str("id", req.Id).
+			Msg(constant.ImageIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+	}
+
+	var image model.Image
+	err = s.repository.Restore(req.Id, &image)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "restore").
+			Str("id", req.Id).
+			Msg(constant.RestoreImageErrorMessage)
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, constant.DeletedImageNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.RestoreImageErrorMessage)
+	}
+
+	return &proto.RestoreImageResponse{Image: RawToDto(&image)}, nil
+}
+
+func (s *serviceImpl) CreateUploadUrl(_ context.Context, req *proto.CreateUploadUrlRequest) (res *proto.CreateUploadUrlResponse, err error) {
+	if req.PetId != "" {
+		_, err = uuid.Parse(req.PetId)
+		if err != nil {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", "create upload url").
+				Str("petId", req.PetId).
+				Msg(constant.PetIdNotUUIDErrorMessage)
+
+			return nil, status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
+		}
+	}
+
+	// the presigned url binds the content type, so the object cannot be stored with another one
+	if !utils.IsAllowedContentType(req.ContentType) {
+		return nil, status.Error(codes.InvalidArgument, constant.UnsupportedImageTypeErrorMessage)
+	}
+
+	// the filename is only kept in its column, the client does not choose where its upload is put
+	objectKey := stagedKeyPrefix + uuid.NewString()
+	expiresAt := time.Now().Add(s.conf.UploadUrlExpiry)
+
+	uploadUrl, err := s.client.PresignUpload(objectKey, req.ContentType, s.conf.UploadUrlExpiry)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "create upload url").
+			Str("petId", req.PetId).
+			Msg(constant.PresignUrlErrorMessage)
+
+		return nil, status.Error(codes.Internal, constant.PresignUrlErrorMessage)
+	}
+
+	imageUrl, err := s.client.ObjectUrl(objectKey)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "create upload url").
+			Str("petId", req.PetId).
+			Msg(constant.PresignUrlErrorMessage)
+
+		return nil, status.Error(codes.Internal, constant.PresignUrlErrorMessage)
+	}
+
+	raw, _ := DtoToRaw(&proto.Image{
+		PetId:     req.PetId,
+		ImageUrl:  imageUrl,
+		ObjectKey: objectKey,
+		Status:    string(constant.PendingImageStatus),
+	})
+	raw.ContentType = req.ContentType
+	raw.Filename = req.Filename
+
+	err = s.repository.Create(raw)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "create upload url").
+			Str("petId", req.PetId).
+			Msg(constant.CreateImageErrorMessage)
+
+		return nil, status.Error(codes.Internal, constant.CreateImageErrorMessage)
+	}
+
+	return &proto.CreateUploadUrlResponse{
+		Image:     RawToDto(raw),
+		UploadUrl: uploadUrl,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+func (s *serviceImpl) ConfirmUpload(ctx context.Context, req *proto.ConfirmUploadRequest) (res *proto.ConfirmUploadResponse, err error) {
+	_, err = uuid.Parse(req.Id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "confirm upload").
+			Str("id", req.Id).
+			Msg(constant.ImageIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+	}
+
+	var image model.Image
+
+	err = s.repository.FindOne(req.Id, &image)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "confirm upload").
+			Str("id", req.Id).
+			Msg("Error finding image from repo")
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	if image.Status == constant.ReadyImageStatus {
+		return &proto.ConfirmUploadResponse{Image: RawToDto(&image)}, nil
+	}
+
+	info, err := s.client.Head(image.ObjectKey)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "confirm upload").
+			Str("id", req.Id).
+			Msg("Error finding uploaded object in bucket")
+		if err == bucket.ErrObjectNotFound {
+			return nil, status.Error(codes.FailedPrecondition, constant.UploadedObjectNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	staged, err := s.stageUploadedObject(ctx, image.ObjectKey, info)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "confirm upload").
+			Str("id", req.Id).
+			Msg("Error checking uploaded object")
+
+		return nil, err
+	}
+
+	raw := &model.Image{PetID: image.PetID, Filename: image.Filename}
+	err = s.store(ctx, "confirm upload", raw, staged, func(raw *model.Image) error {
+		err := s.repository.ConfirmUpload(req.Id, raw)
+		if err == gorm.ErrRecordNotFound {
+			return status.Error(codes.NotFound, constant.PendingImageNotFoundErrorMessage)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the image does not use the uploaded object anymore, the client could still replace it until the url expires
+	s.discard("confirm upload", image.ObjectKey)
+
+	return &proto.ConfirmUploadResponse{Image: RawToDto(raw)}, nil
+}
+
+// stageUploadedObject validates the object that the client uploaded to a presigned url the same way as an
+// upload through the service and stages a copy of it, so the stored image is the file that was validated even
+// when the client replaces the object afterwards. An object that is not an allowed image is deleted, so it is
+// never served from the bucket.
+func (s *serviceImpl) stageUploadedObject(ctx context.Context, objectKey string, info *bucket.ObjectInfo) (*stagedObject, error) {
+	reject := func(err error) (*stagedObject, error) {
+		s.discard("confirm upload", objectKey)
+		return nil, err
+	}
+
+	// the size is checked before the object is downloaded, so a large one is never read
+	if info.Size == 0 || (s.conf.MaxFileSize > 0 && info.Size > s.conf.MaxFileSize) {
+		_, err := s.validateImage(nil, info.Size)
+		return reject(err)
+	}
+
+	object, err := s.client.Get(objectKey)
+	if err != nil {
+		if err == bucket.ErrObjectNotFound {
+			return nil, status.Error(codes.FailedPrecondition, constant.UploadedObjectNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.DownloadFromBucketErrorMessage)
+	}
+	defer object.Body.Close()
+
+	// the object can be replaced after it was inspected, so it is read up to one byte over the limit to tell
+	// that it grew too large
+	var size byteCounter
+	reader := io.Reader(object.Body)
+	if s.conf.MaxFileSize > 0 {
+		reader = io.LimitReader(object.Body, s.conf.MaxFileSize+1)
+	}
+	reader = io.TeeReader(reader, &size)
+
+	header := make([]byte, imageHeaderSize)
+	n, err := io.ReadFull(reader, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, status.Error(codes.Internal, constant.DownloadFromBucketErrorMessage)
+	}
+	header = header[:n]
+
+	contentType, err := s.validateImage(header, int64(n))
+	if err != nil {
+		return reject(err)
+	}
+
+	staged, err := s.stage(ctx, "confirm upload", io.MultiReader(bytes.NewReader(header), reader), contentType)
+	if s.conf.MaxFileSize > 0 && int64(size) > s.conf.MaxFileSize {
+		if staged != nil {
+			s.discard("confirm upload", staged.key)
+		}
+
+		return reject(status.Error(codes.InvalidArgument, constant.FileTooLargeErrorMessage))
+	}
+	if status.Code(err) == codes.InvalidArgument {
+		return reject(err)
+	}
+
+	return staged, err
+}
+
+func (s *serviceImpl) CreateDownloadUrl(_ context.Context, req *proto.CreateDownloadUrlRequest) (res *proto.CreateDownloadUrlResponse, err error) {
+	_, err = uuid.Parse(req.Id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "create download url").
+			Str("id", req.Id).
+			Msg(constant.ImageIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+	}
+
+	var image model.Image
+
+	err = s.repository.FindOne(req.Id, &image)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "create download url").
+			Str("id", req.Id).
+			Msg("Error finding image from repo")
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	if image.Status == constant.PendingImageStatus {
+		return nil, status.Error(codes.FailedPrecondition, constant.UploadNotConfirmedErrorMessage)
+	}
+
+	expiresAt := time.Now().Add(s.conf.DownloadUrlExpiry)
+
+	url, err := s.client.PresignDownload(image.ObjectKey, s.conf.DownloadUrlExpiry)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "create download url").
+			Str("id", req.Id).
+			Msg(constant.PresignUrlErrorMessage)
+
+		return nil, status.Error(codes.Internal, constant.PresignUrlErrorMessage)
+	}
+
+	return &proto.CreateDownloadUrlResponse{
+		Url:       url,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+func (s *serviceImpl) Download(req *proto.DownloadImageRequest, stream proto.ImageService_DownloadServer) error {
+	_, err := uuid.Parse(req.Id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "download").
+			Str("id", req.Id).
+			Msg(constant.ImageIdNotUUIDErrorMessage)
+
+		return status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+	}
+
+	var image model.Image
+
+	err = s.repository.FindOne(req.Id, &image)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "download").
+			Str("id", req.Id).
+			Msg("Error finding image from repo")
+		if err == gorm.ErrRecordNotFound {
+			return status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		}
+
+		return status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	if image.Status == constant.PendingImageStatus {
+		return status.Error(codes.FailedPrecondition, constant.UploadNotConfirmedErrorMessage)
+	}
+
+	object, err := s.client.Get(image.ObjectKey)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "download").
+			Str("id", req.Id).
+			Msg(constant.DownloadFromBucketErrorMessage)
+		if err == bucket.ErrObjectNotFound {
+			return status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage)
+		}
+
+		return status.Error(codes.Internal, constant.DownloadFromBucketErrorMessage)
+	}
+	defer object.Body.Close()
+
+	// only the first message carries the metadata, it is sent even when the file is empty
+	res := &proto.DownloadImageResponse{
+		ContentType: object.ContentType,
+		Size:        object.Size,
+		Etag:        object.ETag,
+	}
+	buffer := make([]byte, downloadChunkSize)
+	for {
+		n, err := io.ReadFull(object.Body, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", "download").
+				Str("id", req.Id).
+				Msg(constant.DownloadFromBucketErrorMessage)
+
+			return status.Error(codes.Internal, constant.DownloadFromBucketErrorMessage)
+		}
+		if n == 0 && res == nil {
+			return nil
+		}
+
+		if res == nil {
+			res = &proto.DownloadImageResponse{}
+		}
+		res.Chunk = buffer[:n]
+
+		if sendErr := stream.Send(res); sendErr != nil {
+			return sendErr
+		}
+		if err != nil {
+			return nil
+		}
+		res = nil
+	}
+}
+
+func (s *serviceImpl) FindSimilar(_ context.Context, req *proto.FindSimilarImagesRequest) (res *proto.FindSimilarImagesResponse, err error) {
+	maxDistance := s.conf.SimilarityThreshold
+	if req.MaxDistance > 0 {
+		maxDistance = int(req.MaxDistance)
+	}
+
+	var similar []*proto.SimilarImage
+	switch {
+	case req.ImageId != "":
+		similar, err = s.findSimilarToImage(req.ImageId, maxDistance)
+	case req.PetId != "":
+		similar, err = s.findSimilarInPet(req.PetId, maxDistance)
+	default:
+		return nil, status.Error(codes.InvalidArgument, constant.SimilarImagesQueryRequiredErrorMessage)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i].Distance < similar[j].Distance
+	})
+
+	return &proto.FindSimilarImagesResponse{Images: similar}, nil
+}
+
+// findSimilarToImage compares the image with the other images of the same pet.
+func (s *serviceImpl) findSimilarToImage(id string, maxDistance int) ([]*proto.SimilarImage, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "find similar").
+			Str("id", id).
+			Msg(constant.ImageIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+	}
+
+	var image model.Image
+	err = s.repository.FindOne(id, &image)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "find similar").
+			Str("id", id).
+			Msg("Error finding image from repo")
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	if image.PetID == nil {
+		return nil, status.Error(codes.FailedPrecondition, constant.ImageNotAssignedErrorMessage)
+	}
+	if image.PerceptualHash == "" {
+		return []*proto.SimilarImage{}, nil
+	}
+
+	images, err := s.findPetImages("find similar", image.PetID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	similar := []*proto.SimilarImage{}
+	for _, candidate := range images {
+		if candidate.ID == image.ID {
+			continue
+		}
+
+		distance, ok := utils.HammingDistance(image.PerceptualHash, candidate.PerceptualHash)
+		if ok && distance <= maxDistance {
+			similar = append(similar, &proto.SimilarImage{
+				Image:       RawToDto(candidate),
+				SimilarToId: image.ID.String(),
+				Distance:    int32(distance),
+			})
+		}
+	}
+
+	return similar, nil
+}
+
+// findSimilarInPet compares every pair of images of the pet, the later image of a pair is reported as similar
+// to the earlier one.
+func (s *serviceImpl) findSimilarInPet(petId string, maxDistance int) ([]*proto.SimilarImage, error) {
+	_, err := uuid.Parse(petId)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", "find similar").
+			Str("petId", petId).
+			Msg(constant.PetIdNotUUIDErrorMessage)
+
+		return nil, status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
+	}
+
+	images, err := s.findPetImages("find similar", petId)
+	if err != nil {
+		return nil, err
+	}
+
+	similar := []*proto.SimilarImage{}
+	for j := range images {
+		for i := 0; i < j; i++ {
+			distance, ok := utils.HammingDistance(images[i].PerceptualHash, images[j].PerceptualHash)
+			if ok && distance <= maxDistance {
+				similar = append(similar, &proto.SimilarImage{
+					Image:       RawToDto(images[j]),
+					SimilarToId: images[i].ID.String(),
+					Distance:    int32(distance),
+				})
+			}
+		}
+	}
+
+	return similar, nil
+}
+
+func (s *serviceImpl) findPetImages(module string, petId string) ([]*model.Image, error) {
+	var images []*model.Image
+
+	err := s.repository.FindByPetId(petId, &images)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", module).
+			Str("petId", petId).
+			Msg("Error finding image by pet id from repo")
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+		}
+
+		return nil, status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	return images, nil
+}
+
+// validateImage checks the size and the header of the uploaded file and returns the detected content type,
+// which is stored with the object instead of the one claimed by the client.
+func (s *serviceImpl) validateImage(header []byte, size int64) (string, error) {
+	if size == 0 {
+		return "", status.Error(codes.InvalidArgument, constant.EmptyFileErrorMessage)
+	}
+	if s.conf.MaxFileSize > 0 && size > s.conf.MaxFileSize {
+		return "", status.Error(codes.InvalidArgument, constant.FileTooLargeErrorMessage)
+	}
+
+	info, err := s.imageUtil.Inspect(header)
+	switch err {
+	case nil:
+	case utils.ErrUnsupportedImageType:
+		return "", status.Error(codes.InvalidArgument, constant.UnsupportedImageTypeErrorMessage)
+	default:
+		return "", status.Error(codes.InvalidArgument, constant.CorruptImageErrorMessage)
+	}
+
+	if (s.conf.MaxWidth > 0 && info.Width > s.conf.MaxWidth) || (s.conf.MaxHeight > 0 && info.Height > s.conf.MaxHeight) {
+		return "", status.Error(codes.InvalidArgument, constant.ImageDimensionsTooLargeErrorMessage)
+	}
+	// the dimensions come from the header, so an image that would take too much memory is never decoded
+	if s.conf.MaxPixels > 0 && int64(info.Width)*int64(info.Height) > s.conf.MaxPixels {
+		return "", status.Error(codes.InvalidArgument, constant.ImageDimensionsTooLargeErrorMessage)
+	}
+
+	return info.ContentType, nil
+}
+
+// sanitizeImage removes the metadata of the uploaded image and applies its orientation when it is enabled,
+// the content type changes when the image has to be converted for that.
+func (s *serviceImpl) sanitizeImage(file []byte, contentType string) ([]byte, string, error) {
+	if !s.conf.StripMetadata {
+		return file, contentType, nil
+	}
+
+	sanitized, sanitizedType, err := s.imageUtil.Sanitize(file, contentType)
+	if err != nil {
+		return nil, "", sanitizeError(err)
+	}
+
+	return sanitized, sanitizedType, nil
+}
+
+// sanitizeError converts the error of removing the metadata of an image to the status of the response.
+func sanitizeError(err error) error {
+	switch err {
+	case utils.ErrMalformedImage:
+		return status.Error(codes.InvalidArgument, constant.CorruptImageErrorMessage)
+	case utils.ErrHeicMetadata:
+		return status.Error(codes.InvalidArgument, constant.HeicMetadataErrorMessage)
+	default:
+		return status.Error(codes.Internal, constant.SanitizeImageErrorMessage)
+	}
+}
+
+// stagedObject is an uploaded file that is kept under a temporary key until it is stored under its checksum.
+type stagedObject struct {
+	key         string
+	checksum    string
+	contentType string
+	size        int64
+}
+
+// stage streams the uploaded file to a temporary key while its checksum is computed and its metadata is
+// scanned, so the file is never kept in memory. When metadata has to be removed the object is streamed again
+// without it, only an image whose orientation has to be applied is read back into memory to be decoded.
+func (s *serviceImpl) stage(ctx context.Context, module string, reader io.Reader, contentType string) (*stagedObject, error) {
+	if !s.conf.StripMetadata {
+		return s.uploadStaged(ctx, module, reader, contentType, io.Discard)
+	}
+
+	scanner, err := s.imageUtil.ScanMetadata(contentType)
+	if err != nil {
+		return nil, sanitizeError(err)
+	}
+
+	staged, err := s.uploadStaged(ctx, module, reader, contentType, scanner)
+	scan, scanErr := scanner.Close()
+	if err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		log.Error().Err(scanErr).
+			Str("service", "image").
+			Str("module", module).
+			Msg(constant.SanitizeImageErrorMessage)
+		s.discard(module, staged.key)
+
+		return nil, sanitizeError(scanErr)
+	}
+
+	switch {
+	case scan.Orientation != 1:
+		return s.restage(ctx, module, staged, func(body io.Reader) (io.ReadCloser, string, error) {
+			file, err := io.ReadAll(body)
+			if err != nil {
+				return nil, "", err
+			}
+
+			sanitized, sanitizedType, err := s.imageUtil.Sanitize(file, contentType)
+			if err != nil {
+				return nil, "", err
+			}
+
+			return io.NopCloser(bytes.NewReader(sanitized)), sanitizedType, nil
+		})
+	case scan.Stripped:
+		return s.restage(ctx, module, staged, func(body io.Reader) (io.ReadCloser, string, error) {
+			return s.imageUtil.StripMetadata(body, contentType, scan), contentType, nil
+		})
+	default:
+		return staged, nil
+	}
+}
+
+// restage replaces the staged object with the file that transform makes of it.
+func (s *serviceImpl) restage(ctx context.Context, module string, staged *stagedObject, transform func(io.Reader) (io.ReadCloser, string, error)) (*stagedObject, error) {
+	defer s.discard(module, staged.key)
+
+	object, err := s.client.Get(staged.key)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", module).
+			Str("objectKey", staged.key).
+			Msg(constant.DownloadFromBucketErrorMessage)
+
+		return nil, status.Error(codes.Internal, constant.SanitizeImageErrorMessage)
+	}
+	defer object.Body.Close()
+
+	reader, contentType, err := transform(object.Body)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", module).
+			Str("objectKey", staged.key).
+			Msg(constant.SanitizeImageErrorMessage)
+
+		return nil, sanitizeError(err)
+	}
+	defer reader.Close()
+
+	return s.uploadStaged(ctx, module, reader, contentType, io.Discard)
+}
+
+// uploadStaged uploads the file to a new temporary key and writes it to w while it is read.
+func (s *serviceImpl) uploadStaged(ctx context.Context, module string, reader io.Reader, contentType string, w io.Writer) (*stagedObject, error) {
+	hash := sha256.New()
+	var size byteCounter
+
+	_, key, err := s.client.UploadStream(ctx, io.TeeReader(reader, io.MultiWriter(hash, &size, w)), stagedKeyPrefix+uuid.NewString(), contentType)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", module).
+			Msg(constant.UploadToBucketErrorMessage)
+
+		return nil, status.Error(codes.Internal, constant.UploadToBucketErrorMessage)
+	}
+
+	return &stagedObject{
+		key:         key,
+		checksum:    fmt.Sprintf("%x", hash.Sum(nil)),
+		contentType: contentType,
+		size:        int64(size),
+	}, nil
+}
+
+// discard deletes a staged object that is not needed anymore, the reconciliation job deletes the ones that are
+// left behind.
+func (s *serviceImpl) discard(module string, key string) {
+	err := s.client.Delete(key)
+	if err != nil && err != bucket.ErrObjectNotFound {
+		log.Warn().Err(err).
+			Str("service", "image").
+			Str("module", module).
+			Str("objectKey", key).
+			Msg("Error deleting the staged object")
+	}
+}
+
+// store copies the staged file to its sha-256 checksum so an identical upload reuses the object and the variants
+// of the image that is already stored instead of uploading them again, and saves the image with save once its
+// derived fields are set. The checksum is reserved before anything is copied, so that the jobs cannot delete the
+// objects of a purged image with the same checksum while they are being reused or uploaded again. The staged
+// object is deleted in any case. A status error of save is returned as it is.
+func (s *serviceImpl) store(ctx context.Context, module string, raw *model.Image, staged *stagedObject, save func(*model.Image) error) error {
+	defer s.discard(module, staged.key)
+	checksum := staged.checksum
+
+	petId := ""
+	if raw.PetID != nil {
+		petId = raw.PetID.String()
+	}
+
+	err := s.repository.ReserveObject(checksum, checksum)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", module).
+			Str("petId", petId).
+			Msg("Error reserving the object of the checksum from repo")
+
+		return status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	raw.Checksum = checksum
+	raw.Size = staged.size
+
+	var duplicate model.Image
+	err = s.repository.FindByChecksum(checksum, &duplicate)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", module).
+			Str("petId", petId).
+			Msg("Error finding image by checksum from repo")
+		s.compensate(module, raw)
+
+		return status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	}
+
+	if err == nil {
+		raw.ImageUrl = duplicate.ImageUrl
+		raw.ObjectKey = duplicate.ObjectKey
+		raw.ContentType = duplicate.ContentType
+		raw.PerceptualHash = duplicate.PerceptualHash
+		raw.Width = duplicate.Width
+		raw.Height = duplicate.Height
+		raw.BlurHash = duplicate.BlurHash
+		raw.DominantColor = duplicate.DominantColor
+		for _, v := range duplicate.Variants {
+			raw.Variants = append(raw.Variants, &model.ImageVariant{
+				Width:     v.Width,
+				Height:    v.Height,
+				ImageUrl:  v.ImageUrl,
+				ObjectKey: v.ObjectKey,
+			})
+		}
+	} else {
+		raw.ImageUrl, raw.ObjectKey, err = s.client.Copy(staged.key, checksum)
+		if err != nil {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", module).
+				Str("petId", petId).
+				Msg(constant.UploadToBucketErrorMessage)
+			s.compensate(module, raw)
+
+			return status.Error(codes.Internal, constant.UploadToBucketErrorMessage)
+		}
+		raw.ContentType = staged.contentType
+		img, err := s.decode(raw)
+		if err != nil {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", module).
+				Str("petId", petId).
+				Msg(constant.DownloadFromBucketErrorMessage)
+			s.compensate(module, raw)
+
+			return status.Error(codes.Internal, constant.DownloadFromBucketErrorMessage)
+		}
+		if img != nil {
+			raw.PerceptualHash = s.imageUtil.PerceptualHash(img.Image)
+			s.setPlaceholder(raw, img)
+		}
+
+		raw.Variants, err = s.createVariants(ctx, raw.ObjectKey, img)
+		if err != nil {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", module).
+				Str("petId", petId).
+				Msg(constant.CreateImageVariantErrorMessage)
+			s.compensate(module, raw)
+
+			return status.Error(codes.Internal, constant.CreateImageVariantErrorMessage)
+		}
+	}
+
+	err = save(raw)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", module).
+			Str("petId", petId).
+			Msg(constant.CreateImageErrorMessage)
+		s.compensate(module, raw)
+
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+
+		return status.Error(codes.Internal, constant.CreateImageErrorMessage)
+	}
+
+	return nil
+}
+
+// compensate gives back the reservation of an image that could not be stored and deletes the objects that were
+// uploaded for it, unless another image or upload of the same file uses them. The deletes that still fail after
+// the retries are recorded for the compensation job, as are all of them when it cannot be told whether the
+// objects are used.
+func (s *serviceImpl) compensate(module string, raw *model.Image) {
+	var keys []string
+	if raw.ObjectKey != "" {
+		keys = append(keys, raw.ObjectKey)
+	}
+	for _, variant := range raw.Variants {
+		keys = append(keys, variant.ObjectKey)
+	}
+
+	var failed []*model.FailedCompensation
+	err := s.repository.ReleaseObject(raw.Checksum)
+	if err == nil {
+		_, err = s.repository.DeleteUnreferencedObject(raw.Checksum, func() error {
+			for _, key := range keys {
+				deleteErr := s.deleteWithRetries(key)
+				if deleteErr != nil {
+					failed = append(failed, &model.FailedCompensation{
+						ObjectKey: key,
+						Checksum:  raw.Checksum,
+						Reason:    constant.UploadCompensationReason,
+						Attempts:  max(1, s.conf.Compensation.Retries),
+						LastError: deleteErr.Error(),
+					})
+				}
+			}
+
+			return nil
+		})
+	}
+	if err != nil {
+		failed = nil
+		for _, key := range keys {
+			failed = append(failed, &model.FailedCompensation{ObjectKey: key, Checksum: raw.Checksum, Reason: constant.UploadCompensationReason, LastError: err.Error()})
+		}
+	}
+
+	for _, compensation := range failed {
+		log.Warn().
+			Str("service", "image").
+			Str("module", module).
+			Str("objectKey", compensation.ObjectKey).
+			Str("error", compensation.LastError).
+			Msg("Error deleting the object of an image that could not be stored")
+	}
+	if len(failed) == 0 {
+		return
+	}
+
+	err = s.repository.CreateFailedCompensations(failed)
+	if err != nil {
+		log.Error().Err(err).
+			Str("service", "image").
+			Str("module", module).
+			Strs("objectKeys", keys).
+			Msg("Error recording failed compensations, the objects are left in the bucket")
+	}
+}
+
+// deleteWithRetries deletes the object and retries with an exponential backoff, an object that does not exist
+// is already deleted.
+func (s *serviceImpl) deleteWithRetries(key string) error {
+	backoff := s.conf.Compensation.Backoff
+
+	var err error
+	for attempt := 0; attempt < max(1, s.conf.Compensation.Retries); attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		err = s.client.Delete(key)
+		if err == nil || err == bucket.ErrObjectNotFound {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// decode reads the stored object and decodes it once for the hash, the placeholders and the variants, and sets
+// the dimensions of the image. It is nil for the images that cannot be decoded, they only get their dimensions
+// from the header and are left out of the similarity search.
+func (s *serviceImpl) decode(raw *model.Image) (*utils.DecodedImage, error) {
+	object, err := s.client.Get(raw.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	reader := bufio.NewReaderSize(object.Body, imageHeaderSize)
+	peeked, _ := reader.Peek(imageHeaderSize)
+	header := append([]byte{}, peeked...)
+
+	img, err := s.imageUtil.Decode(reader, raw.Size)
+	if err != nil {
+		log.Warn().Err(err).
+			Str("service", "image").
+			Str("module", "decode").
+			Str("objectKey", raw.ObjectKey).
+			Msg("Skip the hash, placeholder and variants of the unsupported image")
+
+		info, err := s.imageUtil.Inspect(header)
+		if err == nil {
+			raw.Width = info.Width
+			raw.Height = info.Height
+		}
+
+		return nil, nil
+	}
+
+	raw.Width = img.Image.Bounds().Dx()
+	raw.Height = img.Image.Bounds().Dy()
+
+	return img, nil
+}
+
+// setPlaceholder stores the placeholders that clients show while the image loads.
+func (s *serviceImpl) setPlaceholder(raw *model.Image, img *utils.DecodedImage) {
+	placeholder := s.imageUtil.Placeholder(img.Image)
+	raw.BlurHash = placeholder.BlurHash
+	raw.DominantColor = placeholder.DominantColor
+}
+
+// createVariants uploads the resized copies of the image next to the original object. Files that are not
+// decodable images get no variants.
+func (s *serviceImpl) createVariants(ctx context.Context, objectKey string, img *utils.DecodedImage) ([]*model.ImageVariant, error) {
+	if img == nil {
+		return nil, nil
+	}
+
+	resized, err := s.imageUtil.CreateVariants(img, s.conf.VariantWidths)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []*model.ImageVariant
+	for _, v := range resized {
+		imageUrl, variantKey, err := s.client.UploadStream(ctx, bytes.NewReader(v.Data), fmt.Sprintf("%v_%vw", objectKey, v.Width), v.ContentType)
+		if err != nil {
+			return variants, err
+		}
+
+		variants = append(variants, &model.ImageVariant{
+			Width:     v.Width,
+			Height:    v.Height,
+			ImageUrl:  imageUrl,
+			ObjectKey: variantKey,
+		})
+	}
+
+	return variants, nil
+}
+
+func DtoToRaw(in *proto.Image) (result *model.Image, err error) {
+	var id uuid.UUID
+	if in.Id != "" {
+		id, err = uuid.Parse(in.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	petId, err := uuid.Parse(in.PetId)
+	if err != nil {
+		return &model.Image{
+			Base: model.Base{
+				ID:        id,
+				CreatedAt: time.Time{},
+				UpdatedAt: time.Time{},
+				DeletedAt: gorm.DeletedAt{},
+			},
+			PetID:     nil,
+			ImageUrl:  in.ImageUrl,
+			ObjectKey: in.ObjectKey,
+			Status:    constant.ImageStatus(in.Status),
+		}, nil
+	}
+
+	return &model.Image{
+		Base: model.Base{
+			ID:        id,
+			CreatedAt: time.Time{},
+			UpdatedAt: time.Time{},
+			DeletedAt: gorm.DeletedAt{},
+		},
+		PetID:     &petId,
+		ImageUrl:  in.ImageUrl,
+		ObjectKey: in.ObjectKey,
+		Status:    constant.ImageStatus(in.Status),
+	}, nil
+}
+
+func RawToDtoList(in *[]*model.Image) []*proto.Image {
+	var result []*proto.Image
+	for _, b := range *in {
+		result = append(result, RawToDto(b))
+	}
+
+	return result
+}
+
+func RawToDto(in *model.Image) *proto.Image {
+	var id string
+	var petId string
+	if in.ID != uuid.Nil {
+		id = in.ID.String()
+	}
+	if in.PetID != nil {
+		petId = in.PetID.String()
+	}
+
+	var variants []*proto.ImageVariant
+	for _, v := range in.Variants {
+		variants = append(variants, &proto.ImageVariant{
+			Width:     int32(v.Width),
+			Height:    int32(v.Height),
+			ImageUrl:  v.ImageUrl,
+			ObjectKey: v.ObjectKey,
+		})
+	}
+
+	return &proto.Image{
+		Id:             id,
+		PetId:          petId,
+		ImageUrl:       in.ImageUrl,
+		ObjectKey:      in.ObjectKey,
+		Status:         string(in.Status),
+		Variants:       variants,
+		PerceptualHash: in.PerceptualHash,
+		Width:          int32(in.Width),
+		Height:         int32(in.Height),
+		BlurHash:       in.BlurHash,
+		DominantColor:  in.DominantColor,
+		Size:           in.Size,
+		ContentType:    in.ContentType,
+		Filename:       in.Filename,
+		Checksum:       in.Checksum,
+		Position:       int32(in.Position),
+		IsCover:        in.IsCover,
+	}
+}
+
+// parseImageIds checks that the ids are uuids and removes the repeated ones, so that the repository can tell
+// a missing image by the number of images it finds.
+func parseImageIds(module string, in []string) ([]string, error) {
+	seen := make(map[uuid.UUID]bool, len(in))
+	ids := make([]string, 0, len(in))
+	for _, id := range in {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			log.Error().Err(err).
+				Str("service", "image").
+				Str("module", module).
+				Str("id", id).
+				Msg(constant.ImageIdNotUUIDErrorMessage)
+
+			return nil, status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+		}
+
+		if !seen[parsed] {
+			seen[parsed] = true
+			ids = append(ids, parsed.String())
+		}
+	}
+
+	return ids, nil
+}
+
+// missingIds are the ids without an image.
+func missingIds(ids []string, images []*model.Image) []string {
+	found := make(map[string]bool, len(images))
+	for _, image := range images {
+		found[image.ID.String()] = true
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	return missing
+}
+
+// imagesNotFoundError tells the client which of the images do not exist in the details of the status.
+func imagesNotFoundError(ids []string) error {
+	st := status.New(codes.NotFound, constant.ImageNotFoundErrorMessage)
+	if len(ids) == 0 {
+		return st.Err()
+	}
+
+	details := make([]protoiface.MessageV1, 0, len(ids))
+	for _, id := range ids {
+		details = append(details, &errdetails.ResourceInfo{ResourceType: "image", ResourceName: id})
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// toFilter checks the filter of the image listing, a missing filter lists every image.
+func toFilter(in *proto.ImageFilter) (*image.Filter, error) {
+	filter := &image.Filter{}
+	if in == nil {
+		return filter, nil
+	}
+
+	if in.PetId != "" {
+		petId, err := uuid.Parse(in.PetId)
+		if err != nil {
+			return nil, err
+		}
+		filter.PetID = &petId
+	}
+
+	switch in.Assignment {
+	case proto.ImageAssignment_IMAGE_ASSIGNMENT_ASSIGNED:
+		assigned := true
+		filter.Assigned = &assigned
+	case proto.ImageAssignment_IMAGE_ASSIGNMENT_UNASSIGNED:
+		assigned := false
+		filter.Assigned = &assigned
+	}
+
+	switch constant.ImageStatus(in.Status) {
+	case "", constant.PendingImageStatus, constant.ReadyImageStatus, constant.MissingImageStatus:
+		filter.Status = constant.ImageStatus(in.Status)
+	default:
+		return nil, fmt.Errorf("unknown image status %q", in.Status)
+	}
+
+	if in.CreatedAfter > 0 {
+		filter.CreatedAfter = time.Unix(in.CreatedAfter, 0)
+	}
+	if in.CreatedBefore > 0 {
+		filter.CreatedBefore = time.Unix(in.CreatedBefore, 0)
+	}
+	if in.MinSize < 0 || in.MaxSize < 0 {
+		return nil, errors.New("negative size")
+	}
+	filter.ContentType = in.ContentType
+	filter.MinSize = in.MinSize
+	filter.MaxSize = in.MaxSize
+
+	return filter, nil
+}
+
+// encodeCursor makes the position in the image listing opaque to clients, the creation time keeps its full
+// precision so that no image is skipped or repeated between pages.
+func encodeCursor(cursor *image.Cursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID.String()))
+}
+
+func decodeCursor(in string) (*image.Cursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(in)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, id, ok := strings.Cut(string(decoded), "|")
+	if !ok {
+		return nil, errors.New("cursor has no id")
+	}
+
+	cursor := &image.Cursor{}
+	cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	cursor.ID, err = uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return cursor, nil
+}
+
+// receiveError logs the error that stopped an upload stream and converts it to the status of the response.
+func receiveError(err error, petId string) error {
+	log.Error().Err(err).
+		Str("service", "image").
+		Str("module", "upload stream").
+		Str("petId", petId).
+		Msg(constant.ReceiveUploadErrorMessage)
+
+	switch err {
+	case errFileTooLarge, errUploadChunkRequired:
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Unknown, constant.ReceiveUploadErrorMessage)
+	}
+}
+
+// uploadStreamReader reads the file chunks of an upload stream until the client closes it or
+// the maximum file size is exceeded. The error that stopped the stream is kept in err.
+type uploadStreamReader struct {
+	stream  proto.ImageService_UploadStreamServer
+	maxSize int64
+	size    int64
+	chunk   []byte
+	err     error
+}
+
+func (r *uploadStreamReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		req, err := r.stream.Recv()
+		if err != nil {
+			r.err = err
+			continue
+		}
+
+		if req.GetMetadata() != nil {
+			r.err = errUploadChunkRequired
+			continue
+		}
+
+		r.chunk = req.GetChunk()
+		r.size += int64(len(r.chunk))
+		if r.maxSize > 0 && r.size > r.maxSize {
+			r.chunk = nil
+			r.err = errFileTooLarge
+		}
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+
+	return n, nil
+}
+
+// byteCounter counts the bytes written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
 }
@@ -1,22 +1,38 @@
 package image
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-faker/faker/v4"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/isd-sgcu/johnjud-file/cfgldr"
+	"github.com/isd-sgcu/johnjud-file/client/bucket"
 	"github.com/isd-sgcu/johnjud-file/constant"
 	"github.com/isd-sgcu/johnjud-file/internal/model"
+	"github.com/isd-sgcu/johnjud-file/internal/utils"
 	mock_bucket "github.com/isd-sgcu/johnjud-file/mocks/client/bucket"
 	mock_image "github.com/isd-sgcu/johnjud-file/mocks/repository/image"
 	mock_random "github.com/isd-sgcu/johnjud-file/mocks/utils"
+	repository "github.com/isd-sgcu/johnjud-file/pkg/repository/image"
 	proto "github.com/isd-sgcu/johnjud-go-proto/johnjud/file/image/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
@@ -24,34 +40,120 @@ import (
 
 type ImageServiceTest struct {
 	suite.Suite
-	file                []byte
-	id                  uuid.UUID
-	petId               uuid.UUID
-	objectKey           string
-	imageUrl            string
-	randomString        string
-	objectKeyWithRandom string
-	findReq             *proto.FindImageByPetIdRequest
-	uploadReq           *proto.UploadImageRequest
-	assignReq           *proto.AssignPetRequest
-	deleteReq           *proto.DeleteImageRequest
-	imageProto          *proto.Image
-	image               *model.Image
-	images              []*model.Image
+	conf           cfgldr.Image
+	file           []byte
+	id             uuid.UUID
+	petId          uuid.UUID
+	objectKey      string
+	imageUrl       string
+	checksum       string
+	perceptualHash string
+	placeholder    *utils.ImagePlaceholder
+	findReq        *proto.FindImageByPetIdRequest
+	uploadReq      *proto.UploadImageRequest
+	assignReq      *proto.AssignPetRequest
+	deleteReq      *proto.DeleteImageRequest
+	imageProto     *proto.Image
+	image          *model.Image
+	images         []*model.Image
 }
 
 func TestImageService(t *testing.T) {
 	suite.Run(t, new(ImageServiceTest))
 }
 
+type uploadStreamMock struct {
+	grpc.ServerStream
+	requests []*proto.UploadImageStreamRequest
+	response *proto.UploadImageResponse
+}
+
+func pngFile(width int, height int) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+
+	return buf.Bytes()
+}
+
+func (m *uploadStreamMock) Context() context.Context {
+	return context.Background()
+}
+
+func (m *uploadStreamMock) Recv() (*proto.UploadImageStreamRequest, error) {
+	if len(m.requests) == 0 {
+		return nil, io.EOF
+	}
+
+	req := m.requests[0]
+	m.requests = m.requests[1:]
+
+	return req, nil
+}
+
+func (m *uploadStreamMock) SendAndClose(res *proto.UploadImageResponse) error {
+	m.response = res
+
+	return nil
+}
+
+type downloadStreamMock struct {
+	grpc.ServerStream
+	responses []*proto.DownloadImageResponse
+}
+
+func (m *downloadStreamMock) Context() context.Context {
+	return context.Background()
+}
+
+func (m *downloadStreamMock) Send(res *proto.DownloadImageResponse) error {
+	m.responses = append(m.responses, &proto.DownloadImageResponse{
+		ContentType: res.ContentType,
+		Size:        res.Size,
+		Etag:        res.Etag,
+		Chunk:       append([]byte{}, res.Chunk...),
+	})
+
+	return nil
+}
+
+func newUploadStreamMock(metadata *proto.UploadImageMetadata, chunks ...[]byte) *uploadStreamMock {
+	stream := &uploadStreamMock{}
+	if metadata != nil {
+		stream.requests = append(stream.requests, &proto.UploadImageStreamRequest{
+			Payload: &proto.UploadImageStreamRequest_Metadata{Metadata: metadata},
+		})
+	}
+	for _, chunk := range chunks {
+		stream.requests = append(stream.requests, &proto.UploadImageStreamRequest{
+			Payload: &proto.UploadImageStreamRequest_Chunk{Chunk: chunk},
+		})
+	}
+
+	return stream
+}
+
 func (t *ImageServiceTest) SetupTest() {
-	t.file = []byte("test")
+	t.conf = cfgldr.Image{
+		MaxFileSize:         64 * 1024,
+		MaxWidth:            1000,
+		MaxHeight:           1000,
+		MaxPixels:           500 * 1000,
+		StripMetadata:       true,
+		UploadUrlExpiry:     15 * time.Minute,
+		DownloadUrlExpiry:   15 * time.Minute,
+		VariantWidths:       []int{160, 480},
+		SimilarityThreshold: 10,
+		Compensation:        cfgldr.Compensation{Retries: 2},
+	}
+	t.file = pngFile(16, 16)
 	t.id = uuid.New()
 	t.petId = uuid.New()
 	t.objectKey = faker.Name()
 	t.imageUrl = faker.URL()
-	t.randomString = "random"
-	t.objectKeyWithRandom = t.objectKey + "_" + t.randomString
+	t.checksum = fmt.Sprintf("%x", sha256.Sum256(t.file))
+	decoded, _ := utils.NewImageUtil().Decode(bytes.NewReader(t.file), int64(len(t.file)))
+	t.perceptualHash = utils.NewImageUtil().PerceptualHash(decoded.Image)
+	t.placeholder = utils.NewImageUtil().Placeholder(decoded.Image)
 
 	t.findReq = &proto.FindImageByPetIdRequest{
 		PetId: t.petId.String(),
@@ -132,15 +234,36 @@ func (t *ImageServiceTest) TestFindByPetIdSuccess() {
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
 	imageRepo.On("FindByPetId", t.petId.String(), &images).Return(&t.images, nil)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
 	actual, err := imageService.FindByPetId(context.Background(), t.findReq)
 
 	assert.Nil(t.T(), err)
 	assert.Equal(t.T(), expected, actual)
 }
 
+func (t *ImageServiceTest) TestFindByPetIdPlaceholder() {
+	t.images[0].Width = 640
+	t.images[0].Height = 480
+	t.images[0].BlurHash = "LEHV6nWB2yk8pyo0adR*.7kCMdnj"
+	t.images[0].DominantColor = "#a0522d"
+	var images []*model.Image
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindByPetId", t.petId.String(), &images).Return(&t.images, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindByPetId(context.Background(), t.findReq)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), int32(640), actual.Images[0].Width)
+	assert.Equal(t.T(), int32(480), actual.Images[0].Height)
+	assert.Equal(t.T(), "LEHV6nWB2yk8pyo0adR*.7kCMdnj", actual.Images[0].BlurHash)
+	assert.Equal(t.T(), "#a0522d", actual.Images[0].DominantColor)
+}
+
 func (t *ImageServiceTest) TestFindByPetIdNotFound() {
 	expected := status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
 	var images []*model.Image
@@ -150,9 +273,10 @@ func (t *ImageServiceTest) TestFindByPetIdNotFound() {
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
 	imageRepo.On("FindByPetId", t.petId.String(), &images).Return(nil, gorm.ErrRecordNotFound)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
 	actual, err := imageService.FindByPetId(context.Background(), t.findReq)
 
 	status, ok := status.FromError(err)
@@ -171,9 +295,10 @@ func (t *ImageServiceTest) TestFindByPetIdInternalErr() {
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
 	imageRepo.On("FindByPetId", t.petId.String(), &images).Return(nil, errors.New("Error finding image in db"))
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
 	actual, err := imageService.FindByPetId(context.Background(), t.findReq)
 
 	status, ok := status.FromError(err)
@@ -183,19 +308,401 @@ func (t *ImageServiceTest) TestFindByPetIdInternalErr() {
 	assert.Equal(t.T(), expected.Error(), err.Error())
 }
 
+func (t *ImageServiceTest) TestFindSimilarByPetIdSuccess() {
+	t.images[0].PerceptualHash = "00000000000000ff"
+	t.images[1].PerceptualHash = "00000000000000f0"
+	third := &model.Image{
+		Base:           model.Base{ID: uuid.New()},
+		PetID:          &t.petId,
+		PerceptualHash: "ffffffffffffff00",
+	}
+	fourth := &model.Image{
+		Base:           model.Base{ID: uuid.New()},
+		PetID:          &t.petId,
+		PerceptualHash: "00000000000000fe",
+	}
+	images := append(t.images, third, fourth)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindByPetId", t.petId.String(), mock.AnythingOfType("*[]*model.Image")).Return(&images, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindSimilar(context.Background(), &proto.FindSimilarImagesRequest{PetId: t.petId.String()})
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), actual.Images, 3)
+
+	// the closest pair comes first and the later image of a pair is the one reported
+	assert.Equal(t.T(), fourth.ID.String(), actual.Images[0].Image.Id)
+	assert.Equal(t.T(), t.images[0].ID.String(), actual.Images[0].SimilarToId)
+	assert.Equal(t.T(), int32(1), actual.Images[0].Distance)
+	assert.Equal(t.T(), int32(3), actual.Images[1].Distance)
+	assert.Equal(t.T(), int32(4), actual.Images[2].Distance)
+	assert.Equal(t.T(), t.images[1].ID.String(), actual.Images[2].Image.Id)
+}
+
+func (t *ImageServiceTest) TestFindSimilarByPetIdMaxDistance() {
+	t.images[0].PerceptualHash = "00000000000000ff"
+	t.images[1].PerceptualHash = "00000000000000f0"
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindByPetId", t.petId.String(), mock.AnythingOfType("*[]*model.Image")).Return(&t.images, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindSimilar(context.Background(), &proto.FindSimilarImagesRequest{PetId: t.petId.String(), MaxDistance: 3})
+
+	assert.Nil(t.T(), err)
+	assert.Empty(t.T(), actual.Images)
+}
+
+func (t *ImageServiceTest) TestFindSimilarByImageIdSuccess() {
+	t.image.PerceptualHash = "00000000000000ff"
+	t.images[0].PerceptualHash = "00000000000000fe"
+	t.images[1].PerceptualHash = "ffffffffffffff00"
+	images := append(t.images, t.image)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindOne", t.id.String(), &model.Image{}).Return(t.image, nil)
+	imageRepo.On("FindByPetId", t.petId.String(), mock.AnythingOfType("*[]*model.Image")).Return(&images, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindSimilar(context.Background(), &proto.FindSimilarImagesRequest{ImageId: t.id.String()})
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), actual.Images, 1)
+	assert.Equal(t.T(), t.images[0].ID.String(), actual.Images[0].Image.Id)
+	assert.Equal(t.T(), t.id.String(), actual.Images[0].SimilarToId)
+	assert.Equal(t.T(), int32(1), actual.Images[0].Distance)
+}
+
+func (t *ImageServiceTest) TestFindSimilarImageNotAssigned() {
+	t.image.PetID = nil
+	t.image.PerceptualHash = "00000000000000ff"
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindOne", t.id.String(), &model.Image{}).Return(t.image, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindSimilar(context.Background(), &proto.FindSimilarImagesRequest{ImageId: t.id.String()})
+
+	st, ok := status.FromError(err)
+	assert.True(t.T(), ok)
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), codes.FailedPrecondition, st.Code())
+	assert.Equal(t.T(), constant.ImageNotAssignedErrorMessage, st.Message())
+}
+
+func (t *ImageServiceTest) TestFindSimilarImageNotFound() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindOne", t.id.String(), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindSimilar(context.Background(), &proto.FindSimilarImagesRequest{ImageId: t.id.String()})
+
+	st, ok := status.FromError(err)
+	assert.True(t.T(), ok)
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), codes.NotFound, st.Code())
+}
+
+func (t *ImageServiceTest) TestFindSimilarInvalidArgument() {
+	tests := []struct {
+		name    string
+		req     *proto.FindSimilarImagesRequest
+		message string
+	}{
+		{name: "no id", req: &proto.FindSimilarImagesRequest{}, message: constant.SimilarImagesQueryRequiredErrorMessage},
+		{name: "image id not uuid", req: &proto.FindSimilarImagesRequest{ImageId: "abc"}, message: constant.ImageIdNotUUIDErrorMessage},
+		{name: "pet id not uuid", req: &proto.FindSimilarImagesRequest{PetId: "abc"}, message: constant.PetIdNotUUIDErrorMessage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+			actual, err := imageService.FindSimilar(context.Background(), test.req)
+
+			st, ok := status.FromError(err)
+			assert.True(t.T(), ok)
+			assert.Nil(t.T(), actual)
+			assert.Equal(t.T(), codes.InvalidArgument, st.Code())
+			assert.Equal(t.T(), test.message, st.Message())
+		})
+	}
+}
+
+func (t *ImageServiceTest) TestFindOneSuccess() {
+	t.image.ContentType = "image/png"
+	t.image.Filename = "cat.png"
+	t.image.Size = int64(len(t.file))
+	t.image.Checksum = t.checksum
+	t.image.Width = 16
+	t.image.Height = 16
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindOne", t.id.String(), &model.Image{}).Return(t.image, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindOne(context.Background(), &proto.FindOneImageRequest{Id: t.id.String()})
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), t.id.String(), actual.Image.Id)
+	assert.Equal(t.T(), "image/png", actual.Image.ContentType)
+	assert.Equal(t.T(), "cat.png", actual.Image.Filename)
+	assert.Equal(t.T(), int64(len(t.file)), actual.Image.Size)
+	assert.Equal(t.T(), t.checksum, actual.Image.Checksum)
+	assert.Equal(t.T(), int32(16), actual.Image.Width)
+	assert.Equal(t.T(), int32(16), actual.Image.Height)
+}
+
+func (t *ImageServiceTest) TestFindOneNotFound() {
+	expected := status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindOne", t.id.String(), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindOne(context.Background(), &proto.FindOneImageRequest{Id: t.id.String()})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestFindOneInternalErr() {
+	expected := status.Error(codes.Internal, constant.InternalServerErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindOne", t.id.String(), &model.Image{}).Return(nil, errors.New("Error finding image in db"))
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindOne(context.Background(), &proto.FindOneImageRequest{Id: t.id.String()})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestFindOneIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+
+	imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindOne(context.Background(), &proto.FindOneImageRequest{Id: "abc"})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestFindByIdsSuccess() {
+	missing := uuid.New().String()
+	ids := []string{t.images[1].ID.String(), missing, t.images[0].ID.String()}
+	found := []*model.Image{t.images[0], t.images[1]}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindByIds", ids, mock.AnythingOfType("*[]*model.Image")).Return(&found, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindByIds(context.Background(), &proto.FindImagesByIdsRequest{Ids: ids})
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), actual.Images, 2)
+	assert.Equal(t.T(), t.images[1].ID.String(), actual.Images[0].Id)
+	assert.Equal(t.T(), t.images[0].ID.String(), actual.Images[1].Id)
+}
+
+func (t *ImageServiceTest) TestFindByIdsEmpty() {
+	imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindByIds(context.Background(), &proto.FindImagesByIdsRequest{})
+
+	assert.Nil(t.T(), err)
+	assert.Empty(t.T(), actual.Images)
+}
+
+func (t *ImageServiceTest) TestFindByIdsInvalidArgument() {
+	tooMany := make([]string, maxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New().String()
+	}
+
+	tests := []struct {
+		name    string
+		ids     []string
+		message string
+	}{
+		{name: "id not uuid", ids: []string{t.id.String(), "abc"}, message: constant.ImageIdNotUUIDErrorMessage},
+		{name: "too many ids", ids: tooMany, message: constant.TooManyIdsErrorMessage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			expected := status.Error(codes.InvalidArgument, test.message)
+
+			imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+			actual, err := imageService.FindByIds(context.Background(), &proto.FindImagesByIdsRequest{Ids: test.ids})
+
+			assert.Nil(t.T(), actual)
+			assert.Equal(t.T(), expected, err)
+		})
+	}
+}
+
+func (t *ImageServiceTest) TestFindByIdsInternalErr() {
+	expected := status.Error(codes.Internal, constant.InternalServerErrorMessage)
+	ids := []string{t.id.String()}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("FindByIds", ids, mock.AnythingOfType("*[]*model.Image")).Return(nil, errors.New("Error finding image in db"))
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.FindByIds(context.Background(), &proto.FindImagesByIdsRequest{Ids: ids})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestListFirstPage() {
+	third := &model.Image{Base: model.Base{ID: uuid.New(), CreatedAt: time.Now()}}
+	images := append(t.images, third)
+	assigned := false
+	query := &repository.ListQuery{
+		Filter: repository.Filter{Assigned: &assigned, Status: constant.ReadyImageStatus, MinSize: 1024},
+		Limit:  3,
+	}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("List", query, mock.AnythingOfType("*[]*model.Image")).Return(&images, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.List(context.Background(), &proto.ListImagesRequest{
+		Filter: &proto.ImageFilter{
+			Assignment: proto.ImageAssignment_IMAGE_ASSIGNMENT_UNASSIGNED,
+			Status:     string(constant.ReadyImageStatus),
+			MinSize:    1024,
+		},
+		PageSize: 2,
+	})
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), actual.Images, 2)
+	assert.Equal(t.T(), t.images[1].ID.String(), actual.Images[1].Id)
+	assert.Zero(t.T(), actual.Total)
+
+	cursor, err := decodeCursor(actual.NextCursor)
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), t.images[1].ID, cursor.ID)
+	assert.True(t.T(), t.images[1].CreatedAt.Equal(cursor.CreatedAt))
+}
+
+func (t *ImageServiceTest) TestListLastPage() {
+	after := &repository.Cursor{CreatedAt: time.Now().UTC().Round(0).Add(-time.Hour), ID: uuid.New()}
+	filter := repository.Filter{PetID: &t.petId}
+	total := int64(12)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("List", &repository.ListQuery{Filter: filter, After: after, Ascending: true, Limit: defaultPageSize + 1}, mock.AnythingOfType("*[]*model.Image")).Return(&t.images, nil)
+	imageRepo.On("Count", &filter, mock.AnythingOfType("*int64")).Return(&total, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.List(context.Background(), &proto.ListImagesRequest{
+		Filter:       &proto.ImageFilter{PetId: t.petId.String()},
+		Order:        proto.ListImagesOrder_LIST_IMAGES_ORDER_OLDEST_FIRST,
+		Cursor:       encodeCursor(after),
+		IncludeTotal: true,
+	})
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), actual.Images, 2)
+	assert.Empty(t.T(), actual.NextCursor)
+	assert.Equal(t.T(), total, actual.Total)
+}
+
+func (t *ImageServiceTest) TestListInvalidArgument() {
+	tests := []struct {
+		name    string
+		req     *proto.ListImagesRequest
+		message string
+	}{
+		{name: "page size too large", req: &proto.ListImagesRequest{PageSize: maxPageSize + 1}, message: constant.PageSizeTooLargeErrorMessage},
+		{name: "cursor not base64", req: &proto.ListImagesRequest{Cursor: "!"}, message: constant.InvalidCursorErrorMessage},
+		{name: "cursor without id", req: &proto.ListImagesRequest{Cursor: "MjAyNA"}, message: constant.InvalidCursorErrorMessage},
+		{name: "pet id not uuid", req: &proto.ListImagesRequest{Filter: &proto.ImageFilter{PetId: "abc"}}, message: constant.InvalidImageFilterErrorMessage},
+		{name: "unknown status", req: &proto.ListImagesRequest{Filter: &proto.ImageFilter{Status: "deleted"}}, message: constant.InvalidImageFilterErrorMessage},
+		{name: "negative size", req: &proto.ListImagesRequest{Filter: &proto.ImageFilter{MinSize: -1}}, message: constant.InvalidImageFilterErrorMessage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			expected := status.Error(codes.InvalidArgument, test.message)
+
+			imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+			actual, err := imageService.List(context.Background(), test.req)
+
+			assert.Nil(t.T(), actual)
+			assert.Equal(t.T(), expected, err)
+		})
+	}
+}
+
+func (t *ImageServiceTest) TestListInternalErr() {
+	expected := status.Error(codes.Internal, constant.InternalServerErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("List", mock.AnythingOfType("*image.ListQuery"), mock.AnythingOfType("*[]*model.Image")).Return(nil, errors.New("Error listing images in db"))
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.List(context.Background(), &proto.ListImagesRequest{})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+// stagedKey matches the temporary key that an upload is streamed to before it is stored.
+type stagedKey struct{}
+
+func (stagedKey) Matches(x interface{}) bool {
+	key, ok := x.(string)
+	return ok && strings.HasPrefix(key, stagedKeyPrefix)
+}
+
+func (stagedKey) String() string {
+	return "is a staged key"
+}
+
+// expectStaged expects the upload to be streamed to a temporary key that is deleted in the end.
+func (t *ImageServiceTest) expectStaged(bucketClient *mock_bucket.MockClient) {
+	bucketClient.EXPECT().UploadStream(gomock.Any(), gomock.Any(), stagedKey{}, "image/png").DoAndReturn(func(_ context.Context, reader io.Reader, key string, _ string) (string, string, error) {
+		_, err := io.Copy(io.Discard, reader)
+		return t.imageUrl, key, err
+	})
+	bucketClient.EXPECT().Delete(stagedKey{}).Return(nil)
+}
+
+// expectStored expects the staged file to be copied to its checksum and read back once to be decoded.
+func (t *ImageServiceTest) expectStored(bucketClient *mock_bucket.MockClient, file []byte, checksum string) {
+	t.expectStaged(bucketClient)
+	bucketClient.EXPECT().Copy(stagedKey{}, checksum).Return(t.imageProto.ImageUrl, checksum, nil)
+	bucketClient.EXPECT().Get(checksum).Return(&bucket.Object{Body: io.NopCloser(bytes.NewReader(file)), ContentType: "image/png", Size: int64(len(file))}, nil)
+}
+
 func (t *ImageServiceTest) TestUploadSuccess() {
 	expected := &proto.UploadImageResponse{
 		Image: &proto.Image{
 			Id:        t.imageProto.Id,
 			PetId:     t.imageProto.PetId,
 			ImageUrl:  t.imageProto.ImageUrl,
-			ObjectKey: t.imageProto.ObjectKey + "_" + t.randomString,
+			ObjectKey: t.checksum,
 		},
 	}
 	createImage := &model.Image{
-		PetID:     t.image.PetID,
-		ImageUrl:  t.image.ImageUrl,
-		ObjectKey: t.image.ObjectKey + "_" + t.randomString,
+		PetID:          t.image.PetID,
+		ImageUrl:       t.image.ImageUrl,
+		ObjectKey:      t.checksum,
+		Checksum:       t.checksum,
+		PerceptualHash: t.perceptualHash,
+		Width:          16,
+		Height:         16,
+		BlurHash:       t.placeholder.BlurHash,
+		DominantColor:  t.placeholder.DominantColor,
+		ContentType:    "image/png",
+		Filename:       t.objectKey,
+		Size:           int64(len(t.file)),
 	}
 	createImageReturn := &model.Image{
 		Base: model.Base{
@@ -205,7 +712,7 @@ func (t *ImageServiceTest) TestUploadSuccess() {
 		},
 		PetID:     &t.petId,
 		ImageUrl:  t.imageUrl,
-		ObjectKey: t.objectKey + "_" + t.randomString,
+		ObjectKey: t.checksum,
 	}
 
 	controller := gomock.NewController(t.T())
@@ -213,11 +720,13 @@ func (t *ImageServiceTest) TestUploadSuccess() {
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
-	randomUtils.On("GenerateRandomString", 10).Return(t.randomString, nil)
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
 	imageRepo.On("Create", createImage).Return(createImageReturn, nil)
-	bucketClient.EXPECT().Upload(t.uploadReq.Data, t.objectKeyWithRandom).Return(t.imageProto.ImageUrl, t.objectKeyWithRandom, nil)
+	t.expectStored(bucketClient, t.file, t.checksum)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
 	actual, err := imageService.Upload(context.Background(), t.uploadReq)
 
 	assert.Nil(t.T(), err)
@@ -229,7 +738,7 @@ func (t *ImageServiceTest) TestUploadSuccessNoPetID() {
 		Image: &proto.Image{
 			Id:        t.imageProto.Id,
 			ImageUrl:  t.imageProto.ImageUrl,
-			ObjectKey: t.imageProto.ObjectKey + "_" + t.randomString,
+			ObjectKey: t.checksum,
 		},
 	}
 	uploadInput := &proto.UploadImageRequest{
@@ -238,8 +747,17 @@ func (t *ImageServiceTest) TestUploadSuccessNoPetID() {
 	}
 
 	createImage := &model.Image{
-		ImageUrl:  t.image.ImageUrl,
-		ObjectKey: t.image.ObjectKey + "_" + t.randomString,
+		ImageUrl:       t.image.ImageUrl,
+		ObjectKey:      t.checksum,
+		Checksum:       t.checksum,
+		PerceptualHash: t.perceptualHash,
+		Width:          16,
+		Height:         16,
+		BlurHash:       t.placeholder.BlurHash,
+		DominantColor:  t.placeholder.DominantColor,
+		ContentType:    "image/png",
+		Filename:       t.objectKey,
+		Size:           int64(len(t.file)),
 	}
 	createImageReturn := &model.Image{
 		Base: model.Base{
@@ -248,7 +766,7 @@ func (t *ImageServiceTest) TestUploadSuccessNoPetID() {
 			UpdatedAt: time.Now(),
 		},
 		ImageUrl:  t.imageUrl,
-		ObjectKey: t.objectKey + "_" + t.randomString,
+		ObjectKey: t.checksum,
 	}
 
 	controller := gomock.NewController(t.T())
@@ -256,11 +774,13 @@ func (t *ImageServiceTest) TestUploadSuccessNoPetID() {
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
-	randomUtils.On("GenerateRandomString", 10).Return(t.randomString, nil)
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
 	imageRepo.On("Create", createImage).Return(createImageReturn, nil)
-	bucketClient.EXPECT().Upload(t.uploadReq.Data, t.objectKeyWithRandom).Return(t.imageProto.ImageUrl, t.objectKeyWithRandom, nil)
+	t.expectStored(bucketClient, t.file, t.checksum)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
 	actual, err := imageService.Upload(context.Background(), uploadInput)
 
 	assert.Nil(t.T(), err)
@@ -280,8 +800,9 @@ func (t *ImageServiceTest) TestUploadPetIdNotUUID() {
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
 	actual, err := imageService.Upload(context.Background(), uploadInput)
 
 	status, ok := status.FromError(err)
@@ -299,10 +820,15 @@ func (t *ImageServiceTest) TestUploadBucketFailed() {
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
-	randomUtils.On("GenerateRandomString", 10).Return(t.randomString, nil)
-	bucketClient.EXPECT().Upload(t.uploadReq.Data, t.objectKeyWithRandom).Return("", "", errors.New("Error uploading to bucket client"))
-
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("ReleaseObject", t.checksum).Return(nil)
+	imageRepo.On("DeleteUnreferencedObject", t.checksum).Return(false, nil)
+	t.expectStaged(bucketClient)
+	bucketClient.EXPECT().Copy(stagedKey{}, t.checksum).Return("", "", errors.New("Error uploading to bucket client"))
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
 	actual, err := imageService.Upload(context.Background(), t.uploadReq)
 
 	status, ok := status.FromError(err)
@@ -315,9 +841,18 @@ func (t *ImageServiceTest) TestUploadBucketFailed() {
 func (t *ImageServiceTest) TestUploadRepoFailed() {
 	expected := status.Error(codes.Internal, constant.CreateImageErrorMessage)
 	createImage := &model.Image{
-		PetID:     t.image.PetID,
-		ImageUrl:  t.image.ImageUrl,
-		ObjectKey: t.image.ObjectKey + "_" + t.randomString,
+		PetID:          t.image.PetID,
+		ImageUrl:       t.image.ImageUrl,
+		ObjectKey:      t.checksum,
+		Checksum:       t.checksum,
+		PerceptualHash: t.perceptualHash,
+		Width:          16,
+		Height:         16,
+		BlurHash:       t.placeholder.BlurHash,
+		DominantColor:  t.placeholder.DominantColor,
+		ContentType:    "image/png",
+		Filename:       t.objectKey,
+		Size:           int64(len(t.file)),
 	}
 
 	controller := gomock.NewController(t.T())
@@ -325,11 +860,16 @@ func (t *ImageServiceTest) TestUploadRepoFailed() {
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
-	randomUtils.On("GenerateRandomString", 10).Return(t.randomString, nil)
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
 	imageRepo.On("Create", createImage).Return(nil, errors.New(constant.CreateImageErrorMessage))
-	bucketClient.EXPECT().Upload(t.uploadReq.Data, t.objectKeyWithRandom).Return(t.imageProto.ImageUrl, t.objectKeyWithRandom, nil)
+	imageRepo.On("ReleaseObject", t.checksum).Return(nil)
+	imageRepo.On("DeleteUnreferencedObject", t.checksum).Return(false, nil)
+	t.expectStored(bucketClient, t.file, t.checksum)
+	bucketClient.EXPECT().Delete(t.checksum).Return(nil)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
 	actual, err := imageService.Upload(context.Background(), t.uploadReq)
 
 	status, ok := status.FromError(err)
@@ -339,257 +879,1664 @@ func (t *ImageServiceTest) TestUploadRepoFailed() {
 	assert.Equal(t.T(), expected.Error(), err.Error())
 }
 
-func (t *ImageServiceTest) TestAssignPetSuccess() {
-	expected := &proto.AssignPetResponse{
-		Success: true,
-	}
-	id1, _ := uuid.Parse(t.assignReq.Ids[0])
-	id2, _ := uuid.Parse(t.assignReq.Ids[1])
-	petId, _ := uuid.Parse(t.assignReq.PetId)
-
-	updateImages := []*model.Image{
+func (t *ImageServiceTest) TestUploadRepoFailedCompensation() {
+	tests := []struct {
+		name        string
+		releaseErr  error
+		objectErr   error
+		deleteErrs  []error
+		compensated []*model.FailedCompensation
+	}{
 		{
-			PetID: &petId,
+			name:       "deleted after a retry",
+			deleteErrs: []error{errors.New("bucket unavailable"), nil},
 		},
 		{
-			PetID: &petId,
+			name:       "recorded when the retries fail",
+			deleteErrs: []error{errors.New("bucket unavailable"), errors.New("bucket unavailable")},
+			compensated: []*model.FailedCompensation{
+				{ObjectKey: t.checksum, Checksum: t.checksum, Reason: constant.UploadCompensationReason, Attempts: 2, LastError: "bucket unavailable"},
+			},
 		},
-	}
-
-	image1 := model.Image{
-		Base: model.Base{
-			ID:        id1,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+		{
+			name:       "recorded when the reservation cannot be released",
+			releaseErr: errors.New("database unavailable"),
+			compensated: []*model.FailedCompensation{
+				{ObjectKey: t.checksum, Checksum: t.checksum, Reason: constant.UploadCompensationReason, LastError: "database unavailable"},
+			},
 		},
-		PetID:     nil,
-		ImageUrl:  faker.URL(),
-		ObjectKey: faker.Name(),
-	}
-	image2 := model.Image{
-		Base: model.Base{
-			ID:        id2,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+		{
+			name:      "recorded when the references cannot be checked",
+			objectErr: errors.New("database unavailable"),
+			compensated: []*model.FailedCompensation{
+				{ObjectKey: t.checksum, Checksum: t.checksum, Reason: constant.UploadCompensationReason, LastError: "database unavailable"},
+			},
 		},
-		PetID:     nil,
-		ImageUrl:  faker.URL(),
-		ObjectKey: faker.Name(),
 	}
 
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			controller := gomock.NewController(t.T())
+
+			imageRepo := &mock_image.ImageRepositoryMock{}
+			bucketClient := mock_bucket.NewMockClient(controller)
+			imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+			imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+			imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, errors.New(constant.CreateImageErrorMessage))
+			imageRepo.On("ReleaseObject", t.checksum).Return(test.releaseErr)
+			if test.releaseErr == nil {
+				imageRepo.On("DeleteUnreferencedObject", t.checksum).Return(false, test.objectErr)
+			}
+			if test.compensated != nil {
+				imageRepo.On("CreateFailedCompensations", test.compensated).Return(nil)
+			}
+			t.expectStored(bucketClient, t.file, t.checksum)
+			for _, err := range test.deleteErrs {
+				bucketClient.EXPECT().Delete(t.checksum).Return(err)
+			}
+
+			imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+			actual, err := imageService.Upload(context.Background(), t.uploadReq)
+
+			assert.Nil(t.T(), actual)
+			assert.Equal(t.T(), status.Error(codes.Internal, constant.CreateImageErrorMessage), err)
+			imageRepo.AssertExpectations(t.T())
+		})
+	}
+}
+
+func (t *ImageServiceTest) TestUploadRepoFailedObjectReferenced() {
 	controller := gomock.NewController(t.T())
 
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
-	randomUtils := &mock_random.RandomUtilMock{}
-	imageRepo.On("Update", id1.String(), updateImages[0]).Return(&image1, nil)
-	imageRepo.On("Update", id2.String(), updateImages[1]).Return(&image2, nil)
-
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
-	actual, err := imageService.AssignPet(context.Background(), t.assignReq)
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, errors.New(constant.CreateImageErrorMessage))
+	imageRepo.On("ReleaseObject", t.checksum).Return(nil)
+	// another upload of the same file stored its image in the meantime
+	imageRepo.On("DeleteUnreferencedObject", t.checksum).Return(true, nil)
+	t.expectStored(bucketClient, t.file, t.checksum)
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Upload(context.Background(), t.uploadReq)
 
-	assert.Nil(t.T(), err)
-	assert.Equal(t.T(), expected, actual)
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), status.Error(codes.Internal, constant.CreateImageErrorMessage), err)
 }
 
-func (t *ImageServiceTest) TestAssignPetNotFound() {
-	expected := status.Error(codes.NotFound, constant.PetIdNotFoundErrorMessage)
+func (t *ImageServiceTest) TestUploadVariantFailedCompensation() {
+	file := pngFile(1000, 500)
+	checksum := fmt.Sprintf("%x", sha256.Sum256(file))
 
-	id1, _ := uuid.Parse(t.assignReq.Ids[0])
-	id2, _ := uuid.Parse(t.assignReq.Ids[1])
-	petId, _ := uuid.Parse(t.assignReq.PetId)
+	controller := gomock.NewController(t.T())
 
-	updateImages := []*model.Image{
-		{
-			PetID: &petId,
-		},
-		{
-			PetID: &petId,
-		},
-	}
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	imageRepo.On("ReserveObject", checksum, checksum).Return(nil)
+	imageRepo.On("FindByChecksum", checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("ReleaseObject", checksum).Return(nil)
+	imageRepo.On("DeleteUnreferencedObject", checksum).Return(false, nil)
+	t.expectStored(bucketClient, file, checksum)
+	bucketClient.EXPECT().UploadStream(gomock.Any(), gomock.Any(), checksum+"_160w", "image/png").Return(t.imageUrl, checksum+"_160w", nil)
+	bucketClient.EXPECT().UploadStream(gomock.Any(), gomock.Any(), checksum+"_480w", "image/png").Return("", "", errors.New("bucket unavailable"))
+	bucketClient.EXPECT().Delete(checksum).Return(nil)
+	bucketClient.EXPECT().Delete(checksum + "_160w").Return(nil)
+
+	t.uploadReq.Data = file
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Upload(context.Background(), t.uploadReq)
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), status.Error(codes.Internal, constant.CreateImageVariantErrorMessage), err)
+}
 
-	image2 := model.Image{
+func (t *ImageServiceTest) TestUploadStoresObjectInBucket() {
+	createImageReturn := &model.Image{
 		Base: model.Base{
-			ID:        id2,
+			ID:        t.id,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
-		PetID:     nil,
-		ImageUrl:  faker.URL(),
-		ObjectKey: faker.Name(),
+		PetID:     &t.petId,
+		ImageUrl:  t.imageUrl,
+		ObjectKey: t.checksum,
 	}
 
-	controller := gomock.NewController(t.T())
-
 	imageRepo := &mock_image.ImageRepositoryMock{}
-	bucketClient := mock_bucket.NewMockClient(controller)
+	bucketClient := bucket.NewMemoryClient()
 	randomUtils := &mock_random.RandomUtilMock{}
-	imageRepo.On("Update", id1.String(), updateImages[0]).Return(nil, gorm.ErrForeignKeyViolated)
-	imageRepo.On("Update", id2.String(), updateImages[1]).Return(&image2, nil)
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(createImageReturn, nil)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
-	actual, err := imageService.AssignPet(context.Background(), t.assignReq)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Upload(context.Background(), t.uploadReq)
 
-	status, ok := status.FromError(err)
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), t.checksum, actual.Image.ObjectKey)
+
+	object, ok := bucketClient.Object(t.checksum)
 	assert.True(t.T(), ok)
-	assert.Nil(t.T(), actual)
-	assert.Equal(t.T(), codes.NotFound, status.Code())
-	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Equal(t.T(), t.file, object.Data)
+	assert.Equal(t.T(), "image/png", object.ContentType)
+	assert.False(t.T(), object.CreatedAt.IsZero())
 }
 
-func (t *ImageServiceTest) TestAssignPetPrimaryKeyErr() {
-	expected := status.Error(codes.InvalidArgument, constant.PrimaryKeyRequiredErrorMessage)
+func (t *ImageServiceTest) TestUploadReservesObjectBeforeUploading() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	// a purged image with the same checksum cannot have its objects deleted by the jobs from here on
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil).Run(func(mock.Arguments) {
+		_, ok := bucketClient.Object(t.checksum)
+		assert.False(t.T(), ok)
+	})
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil).Run(func(mock.Arguments) {
+		_, ok := bucketClient.Object(t.checksum)
+		assert.True(t.T(), ok)
+	})
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	_, err := imageService.Upload(context.Background(), t.uploadReq)
 
-	id1, _ := uuid.Parse(t.assignReq.Ids[0])
-	id2, _ := uuid.Parse(t.assignReq.Ids[1])
+	assert.Nil(t.T(), err)
+	// the staged object is deleted once it is copied
+	assert.Equal(t.T(), []string{t.checksum}, bucketClient.Keys())
+	imageRepo.AssertExpectations(t.T())
+	imageRepo.AssertNotCalled(t.T(), "ReleaseObject", mock.Anything)
+}
 
-	assignPetInput := &proto.AssignPetRequest{
-		Ids:   []string{id1.String(), id2.String()},
-		PetId: "",
+func (t *ImageServiceTest) TestUploadCreatesVariants() {
+	file := pngFile(600, 300)
+	uploadReq := &proto.UploadImageRequest{
+		Filename: t.objectKey,
+		Data:     file,
+		PetId:    t.petId.String(),
 	}
 
-	controller := gomock.NewController(t.T())
-
 	imageRepo := &mock_image.ImageRepositoryMock{}
-	bucketClient := mock_bucket.NewMockClient(controller)
+	bucketClient := bucket.NewMemoryClient()
 	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	imageRepo.On("FindByChecksum", mock.AnythingOfType("string"), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
-	actual, err := imageService.AssignPet(context.Background(), assignPetInput)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Upload(context.Background(), uploadReq)
 
-	status, ok := status.FromError(err)
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), actual.Image.Variants, 2)
+	for i, width := range []int32{160, 480} {
+		variant := actual.Image.Variants[i]
+		assert.Equal(t.T(), width, variant.Width)
+		assert.Equal(t.T(), width/2, variant.Height)
+		assert.Equal(t.T(), fmt.Sprintf("%v_%vw", actual.Image.ObjectKey, width), variant.ObjectKey)
+
+		object, ok := bucketClient.Object(variant.ObjectKey)
+		assert.True(t.T(), ok)
+		assert.Equal(t.T(), "image/png", object.ContentType)
+	}
+
+	created := imageRepo.Calls[2].Arguments.Get(0).(*model.Image)
+	assert.Len(t.T(), created.Variants, 2)
+}
+
+// decodeCounter counts how many times the uploaded file is decoded.
+type decodeCounter struct {
+	utils.ImageUtil
+	decodes int
+}
+
+func (u *decodeCounter) Decode(r io.Reader, size int64) (*utils.DecodedImage, error) {
+	u.decodes++
+	return u.ImageUtil.Decode(r, size)
+}
+
+func (t *ImageServiceTest) TestUploadDecodesOnce() {
+	conf := t.conf
+	conf.VariantWidths = []int{4, 8}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
+	imageUtil := &decodeCounter{ImageUtil: utils.NewImageUtil()}
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, imageUtil, conf)
+	actual, err := imageService.Upload(context.Background(), t.uploadReq)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 1, imageUtil.decodes)
+	assert.Equal(t.T(), t.perceptualHash, actual.Image.PerceptualHash)
+	assert.Equal(t.T(), t.placeholder.BlurHash, actual.Image.BlurHash)
+	assert.Len(t.T(), actual.Image.Variants, 2)
+}
+
+func (t *ImageServiceTest) TestUploadOrientedWebp() {
+	file, err := os.ReadFile("../../utils/testdata/gps_xmp.webp")
+	assert.Nil(t.T(), err)
+	// the orientation tag of the exif chunk is turned from 1 to 6
+	file = bytes.Replace(file, []byte{0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00}, []byte{0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x00}, 1)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	imageRepo.On("ReserveObject", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	imageRepo.On("FindByChecksum", mock.AnythingOfType("string"), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Upload(context.Background(), &proto.UploadImageRequest{Filename: t.objectKey, Data: file})
+
+	// the oriented image is converted to png as there is no webp encoder
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "image/png", actual.Image.ContentType)
+	object, ok := bucketClient.Object(actual.Image.ObjectKey)
 	assert.True(t.T(), ok)
-	assert.Nil(t.T(), actual)
-	assert.Equal(t.T(), codes.InvalidArgument, status.Code())
-	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Equal(t.T(), "image/png", object.ContentType)
+	assert.NotContains(t.T(), string(object.Data), "Exif")
+	_, format, err := image.Decode(bytes.NewReader(object.Data))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "png", format)
 }
 
-func (t *ImageServiceTest) TestAssignPetInternalErr() {
+// heicFile is the header of a HEIC image with its dimensions, enough to pass the validation.
+func heicFile() []byte {
+	file := []byte("\x00\x00\x00\x18ftypmif1\x00\x00\x00\x00mif1heic\x00\x00\x00\x14ispe\x00\x00\x00\x00")
+	file = binary.BigEndian.AppendUint32(file, 320)
+	return binary.BigEndian.AppendUint32(file, 240)
+}
+
+func (t *ImageServiceTest) TestUploadHeicWithStripMetadata() {
+	file, err := os.ReadFile("../../utils/testdata/gps_xmp.heic")
+	assert.Nil(t.T(), err)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	imageRepo.On("ReserveObject", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	imageRepo.On("FindByChecksum", mock.AnythingOfType("string"), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Upload(context.Background(), &proto.UploadImageRequest{Filename: t.objectKey, Data: file})
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), int32(320), actual.Image.Width)
+	assert.Equal(t.T(), int32(240), actual.Image.Height)
+	object, ok := bucketClient.Object(actual.Image.ObjectKey)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "image/heic", object.ContentType)
+	assert.Len(t.T(), object.Data, len(file))
+	assert.NotContains(t.T(), string(object.Data), "GPSLatitude")
+	assert.NotContains(t.T(), string(object.Data), "shot at home")
+}
+
+func (t *ImageServiceTest) TestUploadHeicWithoutStripMetadata() {
+	t.conf.StripMetadata = false
+	file := heicFile()
+	checksum := fmt.Sprintf("%x", sha256.Sum256(file))
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	imageRepo.On("ReserveObject", checksum, checksum).Return(nil)
+	imageRepo.On("FindByChecksum", checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Upload(context.Background(), &proto.UploadImageRequest{Filename: t.objectKey, Data: file})
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), int32(320), actual.Image.Width)
+	assert.Equal(t.T(), int32(240), actual.Image.Height)
+	object, ok := bucketClient.Object(checksum)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "image/heic", object.ContentType)
+}
+
+func (t *ImageServiceTest) TestUploadReusesDuplicate() {
+	duplicate := &model.Image{
+		Base:           model.Base{ID: uuid.New()},
+		ImageUrl:       t.imageUrl,
+		ObjectKey:      t.checksum,
+		ContentType:    "image/png",
+		Checksum:       t.checksum,
+		PerceptualHash: t.perceptualHash,
+		Width:          16,
+		Height:         16,
+		BlurHash:       t.placeholder.BlurHash,
+		DominantColor:  t.placeholder.DominantColor,
+		Variants:       []*model.ImageVariant{{Base: model.Base{ID: uuid.New()}, Width: 160, Height: 80, ObjectKey: t.checksum + "_160w"}},
+	}
+
+	controller := gomock.NewController(t.T())
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(duplicate, nil)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
+	// the staged object of a duplicate is dropped instead of being copied
+	t.expectStaged(bucketClient)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Upload(context.Background(), t.uploadReq)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), t.checksum, actual.Image.ObjectKey)
+	assert.Equal(t.T(), t.imageUrl, actual.Image.ImageUrl)
+	assert.Equal(t.T(), t.petId.String(), actual.Image.PetId)
+
+	// the new image gets its own rows that point to the objects of the duplicate
+	created := imageRepo.Calls[2].Arguments.Get(0).(*model.Image)
+	assert.Equal(t.T(), t.checksum, created.Checksum)
+	assert.Equal(t.T(), t.perceptualHash, created.PerceptualHash)
+	assert.Equal(t.T(), t.placeholder.BlurHash, created.BlurHash)
+	assert.Len(t.T(), created.Variants, 1)
+	assert.Equal(t.T(), uuid.Nil, created.Variants[0].ID)
+	assert.Equal(t.T(), t.checksum+"_160w", created.Variants[0].ObjectKey)
+}
+
+func (t *ImageServiceTest) TestUploadFindByChecksumFailed() {
 	expected := status.Error(codes.Internal, constant.InternalServerErrorMessage)
 
-	id1, _ := uuid.Parse(t.assignReq.Ids[0])
-	id2, _ := uuid.Parse(t.assignReq.Ids[1])
-	petId, _ := uuid.Parse(t.assignReq.PetId)
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, errors.New("Error finding image in db"))
+	imageRepo.On("ReleaseObject", t.checksum).Return(nil)
+	imageRepo.On("DeleteUnreferencedObject", t.checksum).Return(false, nil)
 
-	updateImages := []*model.Image{
-		{
-			PetID: &petId,
-		},
-		{
-			PetID: &petId,
-		},
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Upload(context.Background(), t.uploadReq)
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), bucketClient.Keys())
+}
+
+func (t *ImageServiceTest) TestUploadInvalidImage() {
+	tests := map[string]struct {
+		data     []byte
+		expected string
+	}{
+		"empty":            {data: []byte{}, expected: constant.EmptyFileErrorMessage},
+		"too large":        {data: bytes.Repeat([]byte("a"), int(t.conf.MaxFileSize)+1), expected: constant.FileTooLargeErrorMessage},
+		"unsupported type": {data: []byte("%PDF-1.7\n"), expected: constant.UnsupportedImageTypeErrorMessage},
+		"corrupt":          {data: t.file[:20], expected: constant.CorruptImageErrorMessage},
+		"too wide":         {data: pngFile(t.conf.MaxWidth+1, 1), expected: constant.ImageDimensionsTooLargeErrorMessage},
+		"too high":         {data: pngFile(1, t.conf.MaxHeight+1), expected: constant.ImageDimensionsTooLargeErrorMessage},
+		"too many pixels":  {data: pngFile(t.conf.MaxWidth, t.conf.MaxHeight), expected: constant.ImageDimensionsTooLargeErrorMessage},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func() {
+			expected := status.Error(codes.InvalidArgument, test.expected)
+
+			imageRepo := &mock_image.ImageRepositoryMock{}
+			bucketClient := bucket.NewMemoryClient()
+			randomUtils := &mock_random.RandomUtilMock{}
+			imageUtils := utils.NewImageUtil()
+
+			imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+			actual, err := imageService.Upload(context.Background(), &proto.UploadImageRequest{
+				Filename: t.objectKey,
+				Data:     test.data,
+				PetId:    t.petId.String(),
+			})
+
+			assert.Nil(t.T(), actual)
+			assert.Equal(t.T(), expected.Error(), err.Error())
+			assert.Empty(t.T(), bucketClient.Keys())
+		})
 	}
+}
+
+func (t *ImageServiceTest) TestUploadStripsMetadata() {
+	file, err := os.ReadFile("../../utils/testdata/gps_orientation_6.jpg")
+	assert.Nil(t.T(), err)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	imageRepo.On("FindByChecksum", mock.AnythingOfType("string"), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Upload(context.Background(), &proto.UploadImageRequest{
+		Filename: t.objectKey,
+		Data:     file,
+	})
+	assert.Nil(t.T(), err)
+
+	// the checksum is of the sanitized file that is stored
+	object, ok := bucketClient.Object(actual.Image.ObjectKey)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), fmt.Sprintf("%x", sha256.Sum256(object.Data)), actual.Image.ObjectKey)
+	assert.Equal(t.T(), "image/jpeg", object.ContentType)
+	assert.NotContains(t.T(), string(object.Data), "Exif")
+
+	// the orientation is applied to the pixels
+	config, _, err := image.DecodeConfig(bytes.NewReader(object.Data))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 16, config.Width)
+	assert.Equal(t.T(), 32, config.Height)
+}
+
+func (t *ImageServiceTest) TestUploadKeepsMetadataWhenDisabled() {
+	file, err := os.ReadFile("../../utils/testdata/gps_orientation_6.jpg")
+	assert.Nil(t.T(), err)
+	t.conf.StripMetadata = false
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	imageRepo.On("FindByChecksum", mock.AnythingOfType("string"), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
 
-	image2 := model.Image{
+	stream := newUploadStreamMock(&proto.UploadImageMetadata{Filename: t.objectKey}, file[:100], file[100:])
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err = imageService.UploadStream(stream)
+	assert.Nil(t.T(), err)
+
+	object, ok := bucketClient.Object(stream.response.Image.ObjectKey)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), file, object.Data)
+}
+
+func (t *ImageServiceTest) TestUploadBucketUnavailable() {
+	expected := status.Error(codes.Internal, constant.UploadToBucketErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	bucketClient.SetUploadError(errors.New("bucket unavailable"))
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("ReleaseObject", t.checksum).Return(nil)
+	imageRepo.On("DeleteUnreferencedObject", t.checksum).Return(false, nil)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Upload(context.Background(), t.uploadReq)
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), bucketClient.Keys())
+	imageRepo.AssertNotCalled(t.T(), "Create", mock.Anything)
+}
+
+func (t *ImageServiceTest) TestUploadStreamSuccess() {
+	metadata := &proto.UploadImageMetadata{
+		Filename:    t.objectKey,
+		PetId:       t.petId.String(),
+		ContentType: "application/octet-stream",
+	}
+	createImageReturn := &model.Image{
 		Base: model.Base{
-			ID:        id2,
+			ID:        t.id,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
-		PetID:     nil,
-		ImageUrl:  faker.URL(),
-		ObjectKey: faker.Name(),
+		PetID:     &t.petId,
+		ImageUrl:  t.imageUrl,
+		ObjectKey: t.checksum,
+	}
+	expected := &proto.UploadImageResponse{
+		Image: &proto.Image{
+			Id:        t.id.String(),
+			PetId:     t.petId.String(),
+			ImageUrl:  t.imageUrl,
+			ObjectKey: t.checksum,
+		},
 	}
 
-	controller := gomock.NewController(t.T())
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(createImageReturn, nil)
+
+	stream := newUploadStreamMock(metadata, t.file[:10], t.file[10:])
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err := imageService.UploadStream(stream)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), expected, stream.response)
+
+	object, ok := bucketClient.Object(t.checksum)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), t.file, object.Data)
+	assert.Equal(t.T(), "image/png", object.ContentType)
+}
+
+func (t *ImageServiceTest) TestUploadStreamStripsMetadata() {
+	file, err := os.ReadFile("../../utils/testdata/gps_orientation_6.jpg")
+	assert.Nil(t.T(), err)
 
 	imageRepo := &mock_image.ImageRepositoryMock{}
-	bucketClient := mock_bucket.NewMockClient(controller)
+	bucketClient := bucket.NewMemoryClient()
 	randomUtils := &mock_random.RandomUtilMock{}
-	imageRepo.On("Update", id1.String(), updateImages[0]).Return(nil, errors.New("Error updating image in db"))
-	imageRepo.On("Update", id2.String(), updateImages[1]).Return(&image2, nil)
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	imageRepo.On("FindByChecksum", mock.AnythingOfType("string"), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
-	actual, err := imageService.AssignPet(context.Background(), t.assignReq)
+	stream := newUploadStreamMock(&proto.UploadImageMetadata{Filename: t.objectKey}, file[:100], file[100:])
 
-	status, ok := status.FromError(err)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err = imageService.UploadStream(stream)
+	assert.Nil(t.T(), err)
+
+	object, ok := bucketClient.Object(stream.response.Image.ObjectKey)
 	assert.True(t.T(), ok)
-	assert.Nil(t.T(), actual)
-	assert.Equal(t.T(), codes.Internal, status.Code())
+	assert.NotContains(t.T(), string(object.Data), "Exif")
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(object.Data))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 16, config.Width)
+	assert.Equal(t.T(), 32, config.Height)
+}
+
+func (t *ImageServiceTest) TestUploadStreamStripsMetadataWhileStreaming() {
+	file, err := os.ReadFile("../../utils/testdata/gps.jpg")
+	assert.Nil(t.T(), err)
+	sanitized, _, err := utils.NewImageUtil().Sanitize(file, "image/jpeg")
+	assert.Nil(t.T(), err)
+	checksum := fmt.Sprintf("%x", sha256.Sum256(sanitized))
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("ReserveObject", checksum, checksum).Return(nil)
+	imageRepo.On("FindByChecksum", checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
+
+	stream := newUploadStreamMock(&proto.UploadImageMetadata{Filename: t.objectKey}, file[:100], file[100:])
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err = imageService.UploadStream(stream)
+	assert.Nil(t.T(), err)
+
+	// without an orientation the metadata is stripped while streaming and the staged objects are deleted
+	object, ok := bucketClient.Object(checksum)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), sanitized, object.Data)
+	assert.Equal(t.T(), checksum, stream.response.Image.ObjectKey)
+	assert.Equal(t.T(), []string{checksum}, bucketClient.Keys())
+}
+
+func (t *ImageServiceTest) TestUploadStreamMetadataRequired() {
+	expected := status.Error(codes.InvalidArgument, constant.UploadMetadataRequiredErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+
+	stream := newUploadStreamMock(nil, t.file)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err := imageService.UploadStream(stream)
+
 	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), bucketClient.Keys())
 }
 
-func (t *ImageServiceTest) TestDeleteSuccess() {
-	expected := &proto.DeleteImageResponse{
-		Success: true,
+func (t *ImageServiceTest) TestUploadStreamPetIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+
+	stream := newUploadStreamMock(&proto.UploadImageMetadata{Filename: t.objectKey, PetId: "not uuid"}, t.file)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err := imageService.UploadStream(stream)
+
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), bucketClient.Keys())
+}
+
+func (t *ImageServiceTest) TestUploadStreamFileTooLarge() {
+	expected := status.Error(codes.InvalidArgument, constant.FileTooLargeErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+
+	chunk := bytes.Repeat([]byte("a"), int(t.conf.MaxFileSize)/2+1)
+	stream := newUploadStreamMock(&proto.UploadImageMetadata{Filename: t.objectKey}, chunk, chunk)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err := imageService.UploadStream(stream)
+
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), bucketClient.Keys())
+	imageRepo.AssertNotCalled(t.T(), "Create", mock.Anything)
+}
+
+func (t *ImageServiceTest) TestUploadStreamUnsupportedImageType() {
+	expected := status.Error(codes.InvalidArgument, constant.UnsupportedImageTypeErrorMessage)
+	metadata := &proto.UploadImageMetadata{
+		Filename:    t.objectKey,
+		ContentType: "image/png",
 	}
 
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+
+	stream := newUploadStreamMock(metadata, []byte("MZ\x90\x00"), []byte("executable"))
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err := imageService.UploadStream(stream)
+
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Nil(t.T(), stream.response)
+	assert.Empty(t.T(), bucketClient.Keys())
+}
+
+func (t *ImageServiceTest) TestUploadStreamMetadataAfterChunk() {
+	expected := status.Error(codes.InvalidArgument, constant.UploadChunkRequiredErrorMessage)
+	metadata := &proto.UploadImageMetadata{Filename: t.objectKey}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+
+	stream := newUploadStreamMock(metadata, t.file)
+	stream.requests = append(stream.requests, &proto.UploadImageStreamRequest{
+		Payload: &proto.UploadImageStreamRequest_Metadata{Metadata: metadata},
+	})
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err := imageService.UploadStream(stream)
+
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), bucketClient.Keys())
+}
+
+func (t *ImageServiceTest) TestAssignPetSuccess() {
+	expected := &proto.AssignPetResponse{
+		Success: true,
+	}
 	controller := gomock.NewController(t.T())
 
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
-	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(t.image, nil)
-	imageRepo.On("Delete", t.image.ID.String()).Return(nil)
-	bucketClient.EXPECT().Delete(t.image.ObjectKey).Return(nil)
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("AssignPet", t.assignReq.PetId, t.assignReq.Ids).Return(nil)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
-	actual, err := imageService.Delete(context.Background(), t.deleteReq)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.AssignPet(context.Background(), t.assignReq)
 
 	assert.Nil(t.T(), err)
 	assert.Equal(t.T(), expected, actual)
+	imageRepo.AssertExpectations(t.T())
+	imageRepo.AssertNotCalled(t.T(), "Update", mock.Anything, mock.Anything)
 }
 
-func (t *ImageServiceTest) TestDeleteBucketFailed() {
-	expected := status.Error(codes.Internal, constant.DeleteFromBucketErrorMessage)
+func (t *ImageServiceTest) TestAssignPetNotFound() {
+	expected := status.Error(codes.NotFound, constant.PetIdNotFoundErrorMessage)
 
 	controller := gomock.NewController(t.T())
 
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
-	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(t.image, nil)
-	imageRepo.On("Delete", t.image.ID.String()).Return(nil)
-	bucketClient.EXPECT().Delete(t.image.ObjectKey).Return(errors.New("Error deleting from bucket client"))
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("AssignPet", t.assignReq.PetId, t.assignReq.Ids).Return(gorm.ErrForeignKeyViolated)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
-	actual, err := imageService.Delete(context.Background(), t.deleteReq)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.AssignPet(context.Background(), t.assignReq)
 
 	status, ok := status.FromError(err)
 	assert.True(t.T(), ok)
 	assert.Nil(t.T(), actual)
-	assert.Equal(t.T(), codes.Internal, status.Code())
+	assert.Equal(t.T(), codes.NotFound, status.Code())
 	assert.Equal(t.T(), expected.Error(), err.Error())
 }
 
-func (t *ImageServiceTest) TestDeleteNotFound() {
-	expected := status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
-
-	controller := gomock.NewController(t.T())
+func (t *ImageServiceTest) TestAssignPetMissingImages() {
+	id1, _ := uuid.Parse(t.assignReq.Ids[0])
 
 	imageRepo := &mock_image.ImageRepositoryMock{}
-	bucketClient := mock_bucket.NewMockClient(controller)
-	randomUtils := &mock_random.RandomUtilMock{}
-	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("AssignPet", t.assignReq.PetId, t.assignReq.Ids).Return(gorm.ErrRecordNotFound)
+	imageRepo.On("FindByIds", t.assignReq.Ids, mock.AnythingOfType("*[]*model.Image")).Return(&[]*model.Image{{Base: model.Base{ID: id1}}}, nil)
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
-	actual, err := imageService.Delete(context.Background(), t.deleteReq)
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.AssignPet(context.Background(), t.assignReq)
 
-	status, ok := status.FromError(err)
+	st, ok := status.FromError(err)
 	assert.True(t.T(), ok)
 	assert.Nil(t.T(), actual)
-	assert.Equal(t.T(), codes.NotFound, status.Code())
-	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Equal(t.T(), codes.NotFound, st.Code())
+	assert.Equal(t.T(), constant.ImageNotFoundErrorMessage, st.Message())
+	imageRepo.AssertExpectations(t.T())
+
+	details := st.Details()
+	assert.Len(t.T(), details, 1)
+	info, ok := details[0].(*errdetails.ResourceInfo)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "image", info.ResourceType)
+	assert.Equal(t.T(), t.assignReq.Ids[1], info.ResourceName)
 }
 
-func (t *ImageServiceTest) TestDeleteInternalErr() {
-	expected := status.Error(codes.Internal, constant.DeleteImageErrorMessage)
+func (t *ImageServiceTest) TestAssignPetIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+
+	imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.AssignPet(context.Background(), &proto.AssignPetRequest{
+		Ids:   []string{t.id.String(), "abc"},
+		PetId: t.petId.String(),
+	})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestAssignPetPrimaryKeyErr() {
+	expected := status.Error(codes.InvalidArgument, constant.PrimaryKeyRequiredErrorMessage)
+
+	id1, _ := uuid.Parse(t.assignReq.Ids[0])
+	id2, _ := uuid.Parse(t.assignReq.Ids[1])
+
+	assignPetInput := &proto.AssignPetRequest{
+		Ids:   []string{id1.String(), id2.String()},
+		PetId: "",
+	}
 
 	controller := gomock.NewController(t.T())
 
 	imageRepo := &mock_image.ImageRepositoryMock{}
 	bucketClient := mock_bucket.NewMockClient(controller)
 	randomUtils := &mock_random.RandomUtilMock{}
-	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(t.image, nil)
-	imageRepo.On("Delete", t.image.ID.String()).Return(errors.New(constant.DeleteImageErrorMessage))
-	bucketClient.EXPECT().Delete(t.image.ObjectKey).Return(nil)
+	imageUtils := utils.NewImageUtil()
 
-	imageService := NewService(bucketClient, imageRepo, randomUtils)
-	actual, err := imageService.Delete(context.Background(), t.deleteReq)
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.AssignPet(context.Background(), assignPetInput)
 
 	status, ok := status.FromError(err)
 	assert.True(t.T(), ok)
 	assert.Nil(t.T(), actual)
-	assert.Equal(t.T(), codes.Internal, status.Code())
+	assert.Equal(t.T(), codes.InvalidArgument, status.Code())
+	assert.Equal(t.T(), expected.Error(), err.Error())
+}
+
+func (t *ImageServiceTest) TestAssignPetInternalErr() {
+	expected := status.Error(codes.Internal, constant.InternalServerErrorMessage)
+
+	controller := gomock.NewController(t.T())
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("AssignPet", t.assignReq.PetId, t.assignReq.Ids).Return(errors.New("Error updating image in db"))
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.AssignPet(context.Background(), t.assignReq)
+
+	status, ok := status.FromError(err)
+	assert.True(t.T(), ok)
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), codes.Internal, status.Code())
 	assert.Equal(t.T(), expected.Error(), err.Error())
 }
+
+func (t *ImageServiceTest) TestUnassignPetSuccess() {
+	ids := []string{t.images[0].ID.String(), t.images[1].ID.String()}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("UnassignPet", ids).Return(nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.UnassignPet(context.Background(), &proto.UnassignPetRequest{Ids: append(ids, ids[0])})
+
+	assert.Nil(t.T(), err)
+	assert.True(t.T(), actual.Success)
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *ImageServiceTest) TestUnassignPetInvalidArgument() {
+	tests := []struct {
+		name    string
+		ids     []string
+		message string
+	}{
+		{name: "no ids", message: constant.ImageIdsRequiredErrorMessage},
+		{name: "id not uuid", ids: []string{t.id.String(), "abc"}, message: constant.ImageIdNotUUIDErrorMessage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			expected := status.Error(codes.InvalidArgument, test.message)
+
+			imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+			actual, err := imageService.UnassignPet(context.Background(), &proto.UnassignPetRequest{Ids: test.ids})
+
+			assert.Nil(t.T(), actual)
+			assert.Equal(t.T(), expected, err)
+		})
+	}
+}
+
+func (t *ImageServiceTest) TestUnassignPetNotFound() {
+	expected := status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("UnassignPet", []string{t.id.String()}).Return(gorm.ErrRecordNotFound)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.UnassignPet(context.Background(), &proto.UnassignPetRequest{Ids: []string{t.id.String()}})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestMoveImagesSuccess() {
+	toPetId := uuid.New()
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("MovePet", t.petId.String(), toPetId.String(), []string{}).Return(nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.MoveImages(context.Background(), &proto.MoveImagesRequest{FromPetId: t.petId.String(), ToPetId: toPetId.String()})
+
+	assert.Nil(t.T(), err)
+	assert.True(t.T(), actual.Success)
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *ImageServiceTest) TestMoveImagesInvalidArgument() {
+	tests := []struct {
+		name    string
+		req     *proto.MoveImagesRequest
+		message string
+	}{
+		{name: "from pet id not uuid", req: &proto.MoveImagesRequest{FromPetId: "abc", ToPetId: t.petId.String()}, message: constant.PetIdNotUUIDErrorMessage},
+		{name: "to pet id not uuid", req: &proto.MoveImagesRequest{FromPetId: t.petId.String(), ToPetId: "abc"}, message: constant.PetIdNotUUIDErrorMessage},
+		{name: "same pet", req: &proto.MoveImagesRequest{FromPetId: t.petId.String(), ToPetId: t.petId.String()}, message: constant.SamePetErrorMessage},
+		{name: "id not uuid", req: &proto.MoveImagesRequest{FromPetId: t.petId.String(), ToPetId: uuid.New().String(), Ids: []string{"abc"}}, message: constant.ImageIdNotUUIDErrorMessage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			expected := status.Error(codes.InvalidArgument, test.message)
+
+			imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+			actual, err := imageService.MoveImages(context.Background(), test.req)
+
+			assert.Nil(t.T(), actual)
+			assert.Equal(t.T(), expected, err)
+		})
+	}
+}
+
+func (t *ImageServiceTest) TestMoveImagesNotFound() {
+	toPetId := uuid.New()
+	ids := []string{t.id.String()}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{name: "image", err: gorm.ErrRecordNotFound, expected: status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)},
+		{name: "pet", err: gorm.ErrForeignKeyViolated, expected: status.Error(codes.NotFound, constant.PetIdNotFoundErrorMessage)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			imageRepo := &mock_image.ImageRepositoryMock{}
+			imageRepo.On("MovePet", t.petId.String(), toPetId.String(), ids).Return(test.err)
+
+			imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+			actual, err := imageService.MoveImages(context.Background(), &proto.MoveImagesRequest{FromPetId: t.petId.String(), ToPetId: toPetId.String(), Ids: ids})
+
+			assert.Nil(t.T(), actual)
+			assert.Equal(t.T(), test.expected, err)
+		})
+	}
+}
+
+func (t *ImageServiceTest) TestReorderSuccess() {
+	ids := []string{t.images[1].ID.String(), t.images[0].ID.String()}
+	reordered := []*model.Image{t.images[1], t.images[0]}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("Reorder", t.petId.String(), ids).Return(nil)
+	imageRepo.On("FindByPetId", t.petId.String(), mock.AnythingOfType("*[]*model.Image")).Return(&reordered, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Reorder(context.Background(), &proto.ReorderImagesRequest{PetId: t.petId.String(), Ids: ids})
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), ids[0], actual.Images[0].Id)
+	assert.Equal(t.T(), ids[1], actual.Images[1].Id)
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *ImageServiceTest) TestReorderIdsMismatch() {
+	expected := status.Error(codes.InvalidArgument, constant.ReorderIdsMismatchErrorMessage)
+	ids := []string{t.images[0].ID.String()}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("Reorder", t.petId.String(), ids).Return(repository.ErrReorderMismatch)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Reorder(context.Background(), &proto.ReorderImagesRequest{PetId: t.petId.String(), Ids: ids})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+	imageRepo.AssertNotCalled(t.T(), "FindByPetId", mock.Anything, mock.Anything)
+}
+
+func (t *ImageServiceTest) TestReorderPetIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
+
+	imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Reorder(context.Background(), &proto.ReorderImagesRequest{PetId: "abc"})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestReorderInternalErr() {
+	expected := status.Error(codes.Internal, constant.UpdateImageErrorMessage)
+	ids := []string{t.images[0].ID.String(), t.images[1].ID.String()}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("Reorder", t.petId.String(), ids).Return(errors.New("Error updating image in db"))
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Reorder(context.Background(), &proto.ReorderImagesRequest{PetId: t.petId.String(), Ids: ids})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestSetCoverSuccess() {
+	cover := *t.image
+	cover.IsCover = true
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("SetCover", t.petId.String(), t.id.String()).Return(nil)
+	imageRepo.On("FindOne", t.id.String(), &model.Image{}).Return(&cover, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.SetCover(context.Background(), &proto.SetCoverImageRequest{PetId: t.petId.String(), Id: t.id.String()})
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), t.id.String(), actual.Image.Id)
+	assert.True(t.T(), actual.Image.IsCover)
+}
+
+func (t *ImageServiceTest) TestSetCoverNotFound() {
+	expected := status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("SetCover", t.petId.String(), t.id.String()).Return(gorm.ErrRecordNotFound)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.SetCover(context.Background(), &proto.SetCoverImageRequest{PetId: t.petId.String(), Id: t.id.String()})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestSetCoverIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+
+	imageService := NewService(bucket.NewMemoryClient(), &mock_image.ImageRepositoryMock{}, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.SetCover(context.Background(), &proto.SetCoverImageRequest{PetId: t.petId.String(), Id: "abc"})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestDeleteSuccess() {
+	expected := &proto.DeleteImageResponse{
+		Success: true,
+	}
+
+	controller := gomock.NewController(t.T())
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("Delete", t.image.ID.String()).Return(nil)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Delete(context.Background(), t.deleteReq)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), expected, actual)
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *ImageServiceTest) TestDeleteKeepsObjectsInBucket() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("Delete", t.image.ID.String()).Return(nil)
+
+	for _, objectKey := range []string{t.objectKey, t.objectKey + "_160w"} {
+		_, _, err := bucketClient.Upload(t.file, objectKey)
+		assert.Nil(t.T(), err)
+	}
+	bucketClient.SetDeleteError(errors.New("bucket unavailable"))
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Delete(context.Background(), t.deleteReq)
+
+	assert.Nil(t.T(), err)
+	assert.True(t.T(), actual.Success)
+	assert.Len(t.T(), bucketClient.Keys(), 2)
+}
+
+func (t *ImageServiceTest) TestDeleteNotFound() {
+	expected := status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+
+	controller := gomock.NewController(t.T())
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("Delete", t.image.ID.String()).Return(gorm.ErrRecordNotFound)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Delete(context.Background(), t.deleteReq)
+
+	status, ok := status.FromError(err)
+	assert.True(t.T(), ok)
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), codes.NotFound, status.Code())
+	assert.Equal(t.T(), expected.Error(), err.Error())
+}
+
+func (t *ImageServiceTest) TestDeleteInternalErr() {
+	expected := status.Error(codes.Internal, constant.DeleteImageErrorMessage)
+
+	controller := gomock.NewController(t.T())
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := mock_bucket.NewMockClient(controller)
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("Delete", t.image.ID.String()).Return(errors.New(constant.DeleteImageErrorMessage))
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.Delete(context.Background(), t.deleteReq)
+
+	status, ok := status.FromError(err)
+	assert.True(t.T(), ok)
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), codes.Internal, status.Code())
+	assert.Equal(t.T(), expected.Error(), err.Error())
+}
+
+func (t *ImageServiceTest) TestDeleteIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Delete(context.Background(), &proto.DeleteImageRequest{Id: "abc"})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+	imageRepo.AssertNotCalled(t.T(), "Delete", mock.Anything)
+}
+
+func (t *ImageServiceTest) TestRestoreSuccess() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("Restore", t.id.String(), &model.Image{}).Return(t.image, nil)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Restore(context.Background(), &proto.RestoreImageRequest{Id: t.id.String()})
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), RawToDto(t.image), actual.Image)
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *ImageServiceTest) TestRestoreNotFound() {
+	expected := status.Error(codes.NotFound, constant.DeletedImageNotFoundErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("Restore", t.id.String(), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Restore(context.Background(), &proto.RestoreImageRequest{Id: t.id.String()})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestRestoreInternalErr() {
+	expected := status.Error(codes.Internal, constant.RestoreImageErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	imageRepo.On("Restore", t.id.String(), &model.Image{}).Return(nil, errors.New("database unavailable"))
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Restore(context.Background(), &proto.RestoreImageRequest{Id: t.id.String()})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+}
+
+func (t *ImageServiceTest) TestRestoreIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.Restore(context.Background(), &proto.RestoreImageRequest{Id: "abc"})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+	imageRepo.AssertNotCalled(t.T(), "Restore", mock.Anything, mock.Anything)
+}
+
+func (t *ImageServiceTest) TestDownloadSuccess() {
+	file := bytes.Repeat([]byte("a"), downloadChunkSize+10)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(t.image, nil)
+
+	_, _, err := bucketClient.UploadStream(context.Background(), bytes.NewReader(file), t.image.ObjectKey, "image/png")
+	assert.Nil(t.T(), err)
+
+	stream := &downloadStreamMock{}
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err = imageService.Download(&proto.DownloadImageRequest{Id: t.image.ID.String()}, stream)
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), stream.responses, 2)
+	assert.Equal(t.T(), "image/png", stream.responses[0].ContentType)
+	assert.Equal(t.T(), int64(len(file)), stream.responses[0].Size)
+	assert.NotEmpty(t.T(), stream.responses[0].Etag)
+	assert.Empty(t.T(), stream.responses[1].ContentType)
+	assert.Equal(t.T(), file, append(stream.responses[0].Chunk, stream.responses[1].Chunk...))
+}
+
+func (t *ImageServiceTest) TestDownloadEmptyFile() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(t.image, nil)
+
+	_, _, err := bucketClient.UploadStream(context.Background(), bytes.NewReader(nil), t.image.ObjectKey, "image/png")
+	assert.Nil(t.T(), err)
+
+	stream := &downloadStreamMock{}
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err = imageService.Download(&proto.DownloadImageRequest{Id: t.image.ID.String()}, stream)
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), stream.responses, 1)
+	assert.Equal(t.T(), int64(0), stream.responses[0].Size)
+	assert.Empty(t.T(), stream.responses[0].Chunk)
+}
+
+func (t *ImageServiceTest) TestDownloadNotFound() {
+	expected := status.Error(codes.NotFound, constant.ImageNotFoundErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+
+	stream := &downloadStreamMock{}
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err := imageService.Download(&proto.DownloadImageRequest{Id: t.image.ID.String()}, stream)
+
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), stream.responses)
+}
+
+func (t *ImageServiceTest) TestDownloadObjectNotFound() {
+	expected := status.Error(codes.NotFound, constant.ObjectNotFoundErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(t.image, nil)
+
+	stream := &downloadStreamMock{}
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err := imageService.Download(&proto.DownloadImageRequest{Id: t.image.ID.String()}, stream)
+
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), stream.responses)
+}
+
+func (t *ImageServiceTest) TestDownloadNotConfirmed() {
+	expected := status.Error(codes.FailedPrecondition, constant.UploadNotConfirmedErrorMessage)
+
+	pending := *t.image
+	pending.Status = constant.PendingImageStatus
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(&pending, nil)
+
+	// the client put a file to the upload url but did not confirm it
+	_, _, err := bucketClient.Upload(t.file, t.image.ObjectKey)
+	assert.Nil(t.T(), err)
+
+	stream := &downloadStreamMock{}
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	err = imageService.Download(&proto.DownloadImageRequest{Id: t.image.ID.String()}, stream)
+
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), stream.responses)
+}
+
+func (t *ImageServiceTest) TestDownloadBucketFailed() {
+	expected := status.Error(codes.Internal, constant.DownloadFromBucketErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	bucketClient.SetGetError(errors.New("bucket unavailable"))
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(t.image, nil)
+
+	stream := &downloadStreamMock{}
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	err := imageService.Download(&proto.DownloadImageRequest{Id: t.image.ID.String()}, stream)
+
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	assert.Empty(t.T(), stream.responses)
+}
+
+func (t *ImageServiceTest) TestDownloadIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	stream := &downloadStreamMock{}
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	err := imageService.Download(&proto.DownloadImageRequest{Id: "abc"}, stream)
+
+	assert.Equal(t.T(), expected, err)
+	assert.Empty(t.T(), stream.responses)
+	imageRepo.AssertNotCalled(t.T(), "FindOne", mock.Anything, mock.Anything)
+}
+
+func (t *ImageServiceTest) TestCreateUploadUrlSuccess() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("Create", mock.MatchedBy(func(image *model.Image) bool {
+		imageUrl, _ := bucketClient.ObjectUrl(image.ObjectKey)
+		return image.ImageUrl == imageUrl && image.PetID != nil && *image.PetID == t.petId &&
+			image.ContentType == "image/png" && image.Filename == t.objectKey && image.Status == constant.PendingImageStatus
+	})).Return(nil, nil)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.CreateUploadUrl(context.Background(), &proto.CreateUploadUrlRequest{
+		Filename:    t.objectKey,
+		PetId:       t.petId.String(),
+		ContentType: "image/png",
+	})
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), string(constant.PendingImageStatus), actual.Image.Status)
+	// the key is generated, the filename of the client is not part of it
+	assert.True(t.T(), strings.HasPrefix(actual.Image.ObjectKey, stagedKeyPrefix))
+	assert.NotContains(t.T(), actual.Image.ObjectKey, t.objectKey)
+	_, err = uuid.Parse(strings.TrimPrefix(actual.Image.ObjectKey, stagedKeyPrefix))
+	assert.Nil(t.T(), err)
+	assert.Contains(t.T(), actual.UploadUrl, "method=PUT")
+	assert.Greater(t.T(), actual.ExpiresAt, time.Now().Unix())
+	assert.Empty(t.T(), bucketClient.Keys())
+}
+
+func (t *ImageServiceTest) TestCreateUploadUrlPetIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.PetIdNotUUIDErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.CreateUploadUrl(context.Background(), &proto.CreateUploadUrlRequest{
+		Filename: t.objectKey,
+		PetId:    "abc",
+	})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected.Error(), err.Error())
+}
+
+func (t *ImageServiceTest) TestCreateUploadUrlUnsupportedImageType() {
+	expected := status.Error(codes.InvalidArgument, constant.UnsupportedImageTypeErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.CreateUploadUrl(context.Background(), &proto.CreateUploadUrlRequest{
+		Filename:    t.objectKey,
+		PetId:       t.petId.String(),
+		ContentType: "application/pdf",
+	})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	imageRepo.AssertNotCalled(t.T(), "Create", mock.Anything)
+}
+
+// pendingUpload is the pending image of a presigned upload with the file that the client put to its key.
+func (t *ImageServiceTest) pendingUpload(bucketClient *bucket.MemoryClient, file []byte, contentType string) *model.Image {
+	pending := *t.image
+	pending.ObjectKey = stagedKeyPrefix + uuid.NewString()
+	pending.Filename = t.objectKey
+	pending.Status = constant.PendingImageStatus
+
+	_, _, err := bucketClient.UploadStream(context.Background(), bytes.NewReader(file), pending.ObjectKey, contentType)
+	assert.Nil(t.T(), err)
+
+	return &pending
+}
+
+// expectConfirmed expects the pending image to be confirmed with the file that was stored like an upload and
+// returns the image that the upload is confirmed with.
+func expectConfirmed(imageRepo *mock_image.ImageRepositoryMock, pending *model.Image) func() *model.Image {
+	imageRepo.On("FindOne", pending.ID.String(), &model.Image{}).Return(pending, nil)
+	imageRepo.On("ReserveObject", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	imageRepo.On("FindByChecksum", mock.AnythingOfType("string"), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("ConfirmUpload", pending.ID.String(), mock.AnythingOfType("*model.Image")).Return(nil, nil)
+
+	return func() *model.Image {
+		for _, call := range imageRepo.Calls {
+			if call.Method == "ConfirmUpload" {
+				return call.Arguments.Get(1).(*model.Image)
+			}
+		}
+
+		return nil
+	}
+}
+
+func (t *ImageServiceTest) TestConfirmUploadSuccess() {
+	file := pngFile(600, 300)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	pending := t.pendingUpload(bucketClient, file, "image/png")
+	confirmed := expectConfirmed(imageRepo, pending)
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.ConfirmUpload(context.Background(), &proto.ConfirmUploadRequest{Id: t.image.ID.String()})
+
+	assert.Nil(t.T(), err)
+	checksum := fmt.Sprintf("%x", sha256.Sum256(file))
+	assert.Equal(t.T(), checksum, actual.Image.Checksum)
+	assert.Equal(t.T(), checksum, actual.Image.ObjectKey)
+	assert.Equal(t.T(), t.objectKey, actual.Image.Filename)
+	assert.Len(t.T(), actual.Image.Variants, 2)
+
+	// the upload key is deleted, only the stored object and its variants are left
+	_, ok := bucketClient.Object(pending.ObjectKey)
+	assert.False(t.T(), ok)
+	assert.ElementsMatch(t.T(), []string{checksum, checksum + "_160w", checksum + "_480w"}, bucketClient.Keys())
+	imageRepo.AssertCalled(t.T(), "ReserveObject", checksum, checksum)
+	assert.Equal(t.T(), pending.PetID, confirmed().PetID)
+}
+
+func (t *ImageServiceTest) TestConfirmUploadMatchesUpload() {
+	file := pngFile(600, 300)
+
+	uploadRepo := &mock_image.ImageRepositoryMock{}
+	uploadRepo.On("ReserveObject", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	uploadRepo.On("FindByChecksum", mock.AnythingOfType("string"), &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	uploadRepo.On("Create", mock.AnythingOfType("*model.Image")).Return(nil, nil)
+
+	uploadService := NewService(bucket.NewMemoryClient(), uploadRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	_, err := uploadService.Upload(context.Background(), &proto.UploadImageRequest{Filename: t.objectKey, Data: file, PetId: t.petId.String()})
+	assert.Nil(t.T(), err)
+	uploaded := uploadRepo.Calls[2].Arguments.Get(0).(*model.Image)
+
+	confirmRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	confirmed := expectConfirmed(confirmRepo, t.pendingUpload(bucketClient, file, "image/png"))
+
+	confirmService := NewService(bucketClient, confirmRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	_, err = confirmService.ConfirmUpload(context.Background(), &proto.ConfirmUploadRequest{Id: t.image.ID.String()})
+	assert.Nil(t.T(), err)
+
+	assert.NotEmpty(t.T(), uploaded.PerceptualHash)
+	assert.NotEmpty(t.T(), uploaded.BlurHash)
+	assert.Equal(t.T(), uploaded, confirmed())
+}
+
+func (t *ImageServiceTest) TestConfirmUploadStripsMetadata() {
+	file, err := os.ReadFile("../../utils/testdata/gps_orientation_6.jpg")
+	assert.Nil(t.T(), err)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	confirmed := expectConfirmed(imageRepo, t.pendingUpload(bucketClient, file, "image/jpeg"))
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.ConfirmUpload(context.Background(), &proto.ConfirmUploadRequest{Id: t.image.ID.String()})
+
+	assert.Nil(t.T(), err)
+	object, ok := bucketClient.Object(actual.Image.ObjectKey)
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), "image/jpeg", object.ContentType)
+	assert.NotContains(t.T(), string(object.Data), "Exif")
+	assert.Equal(t.T(), fmt.Sprintf("%x", sha256.Sum256(object.Data)), confirmed().Checksum)
+	assert.Equal(t.T(), int64(len(object.Data)), confirmed().Size)
+	assert.Equal(t.T(), 16, confirmed().Width)
+	assert.Equal(t.T(), 32, confirmed().Height)
+}
+
+func (t *ImageServiceTest) TestConfirmUploadReusesDuplicate() {
+	duplicate := &model.Image{
+		ImageUrl:    t.imageUrl,
+		ObjectKey:   t.checksum,
+		ContentType: "image/png",
+		Width:       16,
+		Height:      16,
+		Variants:    []*model.ImageVariant{{Width: 160, Height: 160, ImageUrl: t.imageUrl, ObjectKey: t.checksum + "_160w"}},
+	}
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	pending := t.pendingUpload(bucketClient, t.file, "image/png")
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(pending, nil)
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(duplicate, nil)
+	imageRepo.On("ConfirmUpload", t.image.ID.String(), mock.AnythingOfType("*model.Image")).Return(nil, nil)
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.ConfirmUpload(context.Background(), &proto.ConfirmUploadRequest{Id: t.image.ID.String()})
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), t.checksum, actual.Image.ObjectKey)
+	assert.Len(t.T(), actual.Image.Variants, 1)
+	assert.Empty(t.T(), bucketClient.Keys())
+}
+
+func (t *ImageServiceTest) TestConfirmUploadNotPending() {
+	expected := status.Error(codes.NotFound, constant.PendingImageNotFoundErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	pending := t.pendingUpload(bucketClient, t.file, "image/png")
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(pending, nil)
+	imageRepo.On("ReserveObject", t.checksum, t.checksum).Return(nil)
+	imageRepo.On("FindByChecksum", t.checksum, &model.Image{}).Return(nil, gorm.ErrRecordNotFound)
+	// confirmed by another call or deleted by the janitor in the meantime
+	imageRepo.On("ConfirmUpload", t.image.ID.String(), mock.AnythingOfType("*model.Image")).Return(nil, gorm.ErrRecordNotFound)
+	imageRepo.On("ReleaseObject", t.checksum).Return(nil)
+	imageRepo.On("DeleteUnreferencedObject", t.checksum).Return(false, nil)
+
+	imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.ConfirmUpload(context.Background(), &proto.ConfirmUploadRequest{Id: t.image.ID.String()})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+	// the stored objects are compensated and the upload is left for the janitor
+	assert.Equal(t.T(), []string{pending.ObjectKey}, bucketClient.Keys())
+	imageRepo.AssertExpectations(t.T())
+}
+
+func (t *ImageServiceTest) TestConfirmUploadRejectsObject() {
+	large := pngFile(16, 16)
+	t.conf.MaxFileSize = int64(len(large)) - 1
+
+	tests := []struct {
+		name     string
+		file     []byte
+		expected error
+	}{
+		{name: "not an image", file: []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3"), expected: status.Error(codes.InvalidArgument, constant.UnsupportedImageTypeErrorMessage)},
+		{name: "corrupt", file: []byte("\x89PNG\r\n\x1a\n"), expected: status.Error(codes.InvalidArgument, constant.CorruptImageErrorMessage)},
+		{name: "too large", file: large, expected: status.Error(codes.InvalidArgument, constant.FileTooLargeErrorMessage)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			imageRepo := &mock_image.ImageRepositoryMock{}
+			bucketClient := bucket.NewMemoryClient()
+			pending := t.pendingUpload(bucketClient, test.file, "image/png")
+			imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(pending, nil)
+
+			imageService := NewService(bucketClient, imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+			actual, err := imageService.ConfirmUpload(context.Background(), &proto.ConfirmUploadRequest{Id: t.image.ID.String()})
+
+			assert.Nil(t.T(), actual)
+			assert.Equal(t.T(), test.expected, err)
+			assert.Empty(t.T(), bucketClient.Keys())
+			imageRepo.AssertNotCalled(t.T(), "ConfirmUpload", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func (t *ImageServiceTest) TestConfirmUploadObjectNotFound() {
+	expected := status.Error(codes.FailedPrecondition, constant.UploadedObjectNotFoundErrorMessage)
+
+	pending := *t.image
+	pending.Status = constant.PendingImageStatus
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(&pending, nil)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.ConfirmUpload(context.Background(), &proto.ConfirmUploadRequest{Id: t.image.ID.String()})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected.Error(), err.Error())
+	imageRepo.AssertNotCalled(t.T(), "ConfirmUpload", mock.Anything, mock.Anything)
+}
+
+func (t *ImageServiceTest) TestConfirmUploadIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.ConfirmUpload(context.Background(), &proto.ConfirmUploadRequest{Id: "abc"})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+	imageRepo.AssertNotCalled(t.T(), "FindOne", mock.Anything, mock.Anything)
+}
+
+func (t *ImageServiceTest) TestCreateDownloadUrlSuccess() {
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(t.image, nil)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.CreateDownloadUrl(context.Background(), &proto.CreateDownloadUrlRequest{Id: t.image.ID.String()})
+
+	assert.Nil(t.T(), err)
+	assert.Contains(t.T(), actual.Url, "method=GET")
+	assert.Greater(t.T(), actual.ExpiresAt, time.Now().Unix())
+}
+
+func (t *ImageServiceTest) TestCreateDownloadUrlNotConfirmed() {
+	expected := status.Error(codes.FailedPrecondition, constant.UploadNotConfirmedErrorMessage)
+
+	pending := *t.image
+	pending.Status = constant.PendingImageStatus
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+	bucketClient := bucket.NewMemoryClient()
+	randomUtils := &mock_random.RandomUtilMock{}
+	imageUtils := utils.NewImageUtil()
+	imageRepo.On("FindOne", t.image.ID.String(), &model.Image{}).Return(&pending, nil)
+
+	imageService := NewService(bucketClient, imageRepo, randomUtils, imageUtils, t.conf)
+	actual, err := imageService.CreateDownloadUrl(context.Background(), &proto.CreateDownloadUrlRequest{Id: t.image.ID.String()})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected.Error(), err.Error())
+}
+
+func (t *ImageServiceTest) TestCreateDownloadUrlIdNotUUID() {
+	expected := status.Error(codes.InvalidArgument, constant.ImageIdNotUUIDErrorMessage)
+
+	imageRepo := &mock_image.ImageRepositoryMock{}
+
+	imageService := NewService(bucket.NewMemoryClient(), imageRepo, &mock_random.RandomUtilMock{}, utils.NewImageUtil(), t.conf)
+	actual, err := imageService.CreateDownloadUrl(context.Background(), &proto.CreateDownloadUrlRequest{Id: "abc"})
+
+	assert.Nil(t.T(), actual)
+	assert.Equal(t.T(), expected, err)
+	imageRepo.AssertNotCalled(t.T(), "FindOne", mock.Anything, mock.Anything)
+}
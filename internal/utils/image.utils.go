@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const variantJpegQuality = 85
+
+var (
+	ErrUnsupportedImage     = errors.New("unsupported image format")
+	ErrUnsupportedImageType = errors.New("image type is not allowed")
+	ErrCorruptImage         = errors.New("image header cannot be decoded")
+)
+
+// allowedContentTypes are the types of the images that can be uploaded.
+var allowedContentTypes = map[string]bool{
+	"image/jpeg": true, "image/png": true, "image/webp": true, "image/gif": true, "image/heic": true,
+}
+
+// heicBrands are the ftyp brands of HEIF files that hold HEVC coded images.
+var heicBrands = map[string]bool{
+	"heic": true, "heix": true, "hevc": true, "hevx": true,
+	"heim": true, "heis": true, "hevm": true, "hevs": true,
+}
+
+type ImageInfo struct {
+	ContentType string
+	Width       int
+	Height      int
+}
+
+type ImageVariant struct {
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// DecodedImage is an uploaded file decoded once for everything that is computed from its pixels.
+type DecodedImage struct {
+	Image  image.Image
+	Format string
+	// Size is the size of the encoded file.
+	Size int64
+}
+
+type ImageUtil interface {
+	Inspect(header []byte) (*ImageInfo, error)
+	Sanitize(file []byte, contentType string) ([]byte, string, error)
+	ScanMetadata(contentType string) (MetadataScanner, error)
+	StripMetadata(r io.Reader, contentType string, scan *MetadataScan) io.ReadCloser
+	Decode(r io.Reader, size int64) (*DecodedImage, error)
+	CreateVariants(img *DecodedImage, widths []int) ([]*ImageVariant, error)
+	PerceptualHash(img image.Image) string
+	Placeholder(img image.Image) *ImagePlaceholder
+}
+
+func NewImageUtil() ImageUtil {
+	return &imageUtil{}
+}
+
+type imageUtil struct{}
+
+// Inspect detects the type of the image from its magic bytes, only JPEG, PNG, WebP, GIF and HEIC are
+// allowed, and decodes the header for the dimensions. The header has to contain everything up to the
+// dimensions of the image, the rest of the file is not needed.
+func (u *imageUtil) Inspect(header []byte) (*ImageInfo, error) {
+	contentType := sniffContentType(header)
+	switch contentType {
+	case "":
+		return nil, ErrUnsupportedImageType
+	case "image/heic":
+		width, height, ok := heicDimensions(header)
+		if !ok {
+			return nil, ErrCorruptImage
+		}
+
+		return &ImageInfo{ContentType: contentType, Width: width, Height: height}, nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(header))
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrCorruptImage
+	}
+
+	return &ImageInfo{ContentType: contentType, Width: config.Width, Height: config.Height}, nil
+}
+
+// Decode decodes the whole file of the size, HEIC and the other formats without a go decoder are
+// ErrUnsupportedImage.
+func (u *imageUtil) Decode(r io.Reader, size int64) (*DecodedImage, error) {
+	img, format, err := image.Decode(r)
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	return &DecodedImage{Image: img, Format: format, Size: size}, nil
+}
+
+// CreateVariants scales the image down to each of the widths while keeping its aspect ratio. Widths that are
+// not smaller than the original are skipped because upscaling only makes the variant larger.
+func (u *imageUtil) CreateVariants(img *DecodedImage, widths []int) ([]*ImageVariant, error) {
+	src, format := img.Image, img.Format
+	bounds := src.Bounds()
+	var variants []*ImageVariant
+	for _, width := range widths {
+		if width <= 0 || width >= bounds.Dx() {
+			continue
+		}
+
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+		data, contentType, err := encode(dst, format)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, &ImageVariant{
+			Width:       width,
+			Height:      height,
+			ContentType: contentType,
+			Data:        data,
+		})
+	}
+
+	return variants, nil
+}
+
+// encode keeps png and gif variants lossless, and the variants with transparent pixels such as the ones of a webp
+// image with alpha become png too because jpeg has no alpha channel. Everything else becomes jpeg.
+func encode(img *image.RGBA, format string) ([]byte, string, error) {
+	var buf bytes.Buffer
+
+	switch {
+	case format == "png", format == "gif", !img.Opaque():
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	default:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: variantJpegQuality}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+}
+
+// IsAllowedContentType reports whether images of the content type can be uploaded, for uploads where
+// the file cannot be inspected before it is stored.
+func IsAllowedContentType(contentType string) bool {
+	return allowedContentTypes[contentType]
+}
+
+func sniffContentType(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return "image/gif"
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return "image/webp"
+	case isHeic(header):
+		return "image/heic"
+	}
+
+	return ""
+}
+
+// isHeic checks the major and the compatible brands of the ftyp box that starts every HEIF file.
+func isHeic(header []byte) bool {
+	if len(header) < 16 || string(header[4:8]) != "ftyp" {
+		return false
+	}
+
+	size := int(binary.BigEndian.Uint32(header[:4]))
+	if size < 16 || size > len(header) {
+		return false
+	}
+
+	if heicBrands[string(header[8:12])] {
+		return true
+	}
+	// the minor version sits between the major and the compatible brands
+	for i := 16; i+4 <= size; i += 4 {
+		if heicBrands[string(header[i:i+4])] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// heicDimensions reads the image spatial extents (ispe) properties of the file and returns the largest one,
+// smaller ones belong to the thumbnails and the grid tiles of the image.
+func heicDimensions(header []byte) (int, int, bool) {
+	var width, height int
+	for i := 4; i+16 <= len(header); i++ {
+		if string(header[i:i+4]) != "ispe" {
+			continue
+		}
+
+		w := int(binary.BigEndian.Uint32(header[i+8 : i+12]))
+		h := int(binary.BigEndian.Uint32(header[i+12 : i+16]))
+		if w*h > width*height {
+			width, height = w, h
+		}
+	}
+
+	return width, height, width > 0 && height > 0
+}
@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImageUtilTest struct {
+	suite.Suite
+	png  []byte
+	jpeg []byte
+}
+
+func TestImageUtil(t *testing.T) {
+	suite.Run(t, new(ImageUtilTest))
+}
+
+func (t *ImageUtilTest) SetupTest() {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 200; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t.T(), png.Encode(&buf, img))
+	t.png = buf.Bytes()
+
+	buf = bytes.Buffer{}
+	assert.Nil(t.T(), jpeg.Encode(&buf, img, nil))
+	t.jpeg = buf.Bytes()
+}
+
+// decode decodes a file the test encoded, the way the service decodes an upload.
+func (t *ImageUtilTest) decode(file []byte) *DecodedImage {
+	img, err := NewImageUtil().Decode(bytes.NewReader(file), int64(len(file)))
+	assert.Nil(t.T(), err)
+
+	return img
+}
+
+func (t *ImageUtilTest) TestCreateVariantsPng() {
+	variants, err := NewImageUtil().CreateVariants(t.decode(t.png), []int{100, 200})
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), variants, 2)
+	for i, width := range []int{100, 200} {
+		assert.Equal(t.T(), width, variants[i].Width)
+		assert.Equal(t.T(), width/2, variants[i].Height)
+		assert.Equal(t.T(), "image/png", variants[i].ContentType)
+
+		config, format, err := image.DecodeConfig(bytes.NewReader(variants[i].Data))
+		assert.Nil(t.T(), err)
+		assert.Equal(t.T(), "png", format)
+		assert.Equal(t.T(), width, config.Width)
+		assert.Equal(t.T(), width/2, config.Height)
+	}
+}
+
+func (t *ImageUtilTest) TestCreateVariantsJpeg() {
+	variants, err := NewImageUtil().CreateVariants(t.decode(t.jpeg), []int{160})
+
+	assert.Nil(t.T(), err)
+	assert.Len(t.T(), variants, 1)
+	assert.Equal(t.T(), "image/jpeg", variants[0].ContentType)
+	assert.Equal(t.T(), 80, variants[0].Height)
+}
+
+func (t *ImageUtilTest) TestCreateVariantsWebp() {
+	tests := map[string]struct {
+		alpha       uint8
+		contentType string
+		format      string
+	}{
+		"opaque":      {alpha: 255, contentType: "image/jpeg", format: "jpeg"},
+		"transparent": {alpha: 0, contentType: "image/png", format: "png"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func() {
+			// the webp decoder returns an NRGBA image when the file has an alpha channel
+			img := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+			for x := 0; x < 400; x++ {
+				for y := 0; y < 200; y++ {
+					alpha := uint8(255)
+					if x < 200 {
+						alpha = test.alpha
+					}
+					img.SetNRGBA(x, y, color.NRGBA{R: 200, G: 100, B: 50, A: alpha})
+				}
+			}
+
+			variants, err := NewImageUtil().CreateVariants(&DecodedImage{Image: img, Format: "webp"}, []int{100})
+
+			assert.Nil(t.T(), err)
+			assert.Len(t.T(), variants, 1)
+			assert.Equal(t.T(), test.contentType, variants[0].ContentType)
+
+			variant, format, err := image.Decode(bytes.NewReader(variants[0].Data))
+			assert.Nil(t.T(), err)
+			assert.Equal(t.T(), test.format, format)
+			_, _, _, a := variant.At(0, 0).RGBA()
+			assert.Equal(t.T(), uint32(test.alpha)*0x101, a)
+		})
+	}
+}
+
+func (t *ImageUtilTest) TestCreateVariantsSkipUpscale() {
+	variants, err := NewImageUtil().CreateVariants(t.decode(t.png), []int{400, 1080})
+
+	assert.Nil(t.T(), err)
+	assert.Empty(t.T(), variants)
+}
+
+func (t *ImageUtilTest) TestDecode() {
+	img, err := NewImageUtil().Decode(bytes.NewReader(t.jpeg), int64(len(t.jpeg)))
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "jpeg", img.Format)
+	assert.Equal(t.T(), int64(len(t.jpeg)), img.Size)
+	assert.Equal(t.T(), image.Rect(0, 0, 400, 200), img.Image.Bounds())
+}
+
+func (t *ImageUtilTest) TestDecodeUnsupportedImage() {
+	img, err := NewImageUtil().Decode(bytes.NewReader([]byte("test")), 4)
+
+	assert.Nil(t.T(), img)
+	assert.Equal(t.T(), ErrUnsupportedImage, err)
+}
+
+func (t *ImageUtilTest) TestPerceptualHashResized() {
+	util := NewImageUtil()
+	original := util.PerceptualHash(t.decode(t.png).Image)
+	assert.Len(t.T(), original, 16)
+
+	variants, err := util.CreateVariants(t.decode(t.png), []int{160})
+	assert.Nil(t.T(), err)
+	resized := util.PerceptualHash(t.decode(variants[0].Data).Image)
+	recompressed := util.PerceptualHash(t.decode(t.jpeg).Image)
+
+	distance, ok := HammingDistance(original, resized)
+	assert.True(t.T(), ok)
+	assert.LessOrEqual(t.T(), distance, 4)
+	distance, ok = HammingDistance(original, recompressed)
+	assert.True(t.T(), ok)
+	assert.LessOrEqual(t.T(), distance, 4)
+}
+
+func (t *ImageUtilTest) TestPerceptualHashDifferentImage() {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 200; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(255 - x), G: uint8(y * x), B: 0, A: 255})
+		}
+	}
+	util := NewImageUtil()
+	original := util.PerceptualHash(t.decode(t.png).Image)
+	other := util.PerceptualHash(img)
+
+	distance, ok := HammingDistance(original, other)
+	assert.True(t.T(), ok)
+	assert.Greater(t.T(), distance, 10)
+}
+
+func (t *ImageUtilTest) TestHammingDistance() {
+	distance, ok := HammingDistance("00000000000000ff", "000000000000000f")
+	assert.True(t.T(), ok)
+	assert.Equal(t.T(), 4, distance)
+
+	_, ok = HammingDistance("", "000000000000000f")
+	assert.False(t.T(), ok)
+}
+
+func (t *ImageUtilTest) TestPlaceholder() {
+	placeholder := NewImageUtil().Placeholder(t.decode(t.png).Image)
+
+	// 4x3 components for a landscape image
+	assert.Len(t.T(), placeholder.BlurHash, 28)
+	assert.Equal(t.T(), byte('L'), placeholder.BlurHash[0])
+	assert.Regexp(t.T(), "^#[0-9a-f]{6}$", placeholder.DominantColor)
+}
+
+func (t *ImageUtilTest) TestPlaceholderSolidColor() {
+	img := image.NewRGBA(image.Rect(0, 0, 30, 60))
+	for x := 0; x < 30; x++ {
+		for y := 0; y < 60; y++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	placeholder := NewImageUtil().Placeholder(img)
+
+	assert.Equal(t.T(), "#ff0000", placeholder.DominantColor)
+	// 3x4 components for a portrait image and the average colour is red
+	assert.Equal(t.T(), "TI:j", placeholder.BlurHash[2:6])
+	assert.Len(t.T(), placeholder.BlurHash, 28)
+	assert.Equal(t.T(), byte('T'), placeholder.BlurHash[0])
+}
+
+func (t *ImageUtilTest) TestPlaceholderBlurHashReference() {
+	// the expected hashes are the ones of the reference encoder of https://github.com/woltapp/blurhash with 4x3
+	// components, the images have the size of the thumbnail so they are not scaled
+	split := func(first color.RGBA, second color.RGBA, vertical bool) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 32, 24))
+		for x := 0; x < 32; x++ {
+			for y := 0; y < 24; y++ {
+				if (vertical && x < 16) || (!vertical && y < 12) {
+					img.SetRGBA(x, y, first)
+				} else {
+					img.SetRGBA(x, y, second)
+				}
+			}
+		}
+		return img
+	}
+	black := color.RGBA{A: 255}
+	orange := color.RGBA{R: 200, G: 120, B: 60, A: 255}
+	blue := color.RGBA{R: 60, G: 120, B: 200, A: 255}
+
+	tests := map[string]struct {
+		img      *image.RGBA
+		expected string
+	}{
+		"black":                  {img: split(black, black, true), expected: "L00000fQfQfQfQfQfQfQfQfQfQfQ"},
+		"orange left blue right": {img: split(orange, blue, true), expected: "LwHT|7|,sRK7ofn~jsa}fQfQfQfQ"},
+		"orange top blue bottom": {img: split(orange, blue, false), expected: "LxHT|7offQof|-o0fQo0sRjsfQjs"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func() {
+			placeholder := NewImageUtil().Placeholder(test.img)
+
+			assert.Equal(t.T(), test.expected, placeholder.BlurHash)
+		})
+	}
+}
+
+func (t *ImageUtilTest) TestPlaceholderDominantColor() {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for x := 0; x < 100; x++ {
+		for y := 0; y < 100; y++ {
+			img.Set(x, y, color.RGBA{B: 255, A: 255})
+			if x < 30 {
+				img.Set(x, y, color.RGBA{G: 255, A: 255})
+			}
+		}
+	}
+	placeholder := NewImageUtil().Placeholder(img)
+
+	assert.Equal(t.T(), "#0000ff", placeholder.DominantColor)
+}
+
+func (t *ImageUtilTest) TestInspectPng() {
+	info, err := NewImageUtil().Inspect(t.png)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), &ImageInfo{ContentType: "image/png", Width: 400, Height: 200}, info)
+}
+
+func (t *ImageUtilTest) TestInspectJpeg() {
+	info, err := NewImageUtil().Inspect(t.jpeg)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), &ImageInfo{ContentType: "image/jpeg", Width: 400, Height: 200}, info)
+}
+
+func (t *ImageUtilTest) TestInspectGif() {
+	var buf bytes.Buffer
+	assert.Nil(t.T(), gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 30, 20), color.Palette{color.Black, color.White}), nil))
+
+	info, err := NewImageUtil().Inspect(buf.Bytes())
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), &ImageInfo{ContentType: "image/gif", Width: 30, Height: 20}, info)
+}
+
+func (t *ImageUtilTest) TestInspectWebp() {
+	// lossless bitstream header: signature then 14 bits of width - 1 and 14 bits of height - 1
+	bitstream := make([]byte, 5)
+	bitstream[0] = 0x2f
+	binary.LittleEndian.PutUint32(bitstream[1:], uint32(64-1)|uint32(32-1)<<14)
+
+	file := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8L"), binary.LittleEndian.AppendUint32(nil, uint32(len(bitstream)))...)
+	file = append(file, bitstream...)
+	binary.LittleEndian.PutUint32(file[4:8], uint32(len(file)-8))
+
+	info, err := NewImageUtil().Inspect(file)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), &ImageInfo{ContentType: "image/webp", Width: 64, Height: 32}, info)
+}
+
+func (t *ImageUtilTest) TestInspectHeic() {
+	file := []byte("\x00\x00\x00\x18ftypmif1\x00\x00\x00\x00mif1heic")
+	for _, size := range [][2]uint32{{320, 240}, {4032, 3024}} {
+		file = append(file, "\x00\x00\x00\x14ispe\x00\x00\x00\x00"...)
+		file = binary.BigEndian.AppendUint32(file, size[0])
+		file = binary.BigEndian.AppendUint32(file, size[1])
+	}
+
+	info, err := NewImageUtil().Inspect(file)
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), &ImageInfo{ContentType: "image/heic", Width: 4032, Height: 3024}, info)
+}
+
+func (t *ImageUtilTest) TestInspectUnsupportedType() {
+	info, err := NewImageUtil().Inspect([]byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3"))
+
+	assert.Nil(t.T(), info)
+	assert.Equal(t.T(), ErrUnsupportedImageType, err)
+}
+
+func (t *ImageUtilTest) TestInspectCorrupt() {
+	info, err := NewImageUtil().Inspect(append([]byte("\x89PNG\r\n\x1a\n"), "not a png"...))
+
+	assert.Nil(t.T(), info)
+	assert.Equal(t.T(), ErrCorruptImage, err)
+}
@@ -0,0 +1,878 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/webp"
+)
+
+const sanitizedJpegQuality = 92
+
+var (
+	ErrMalformedImage = errors.New("image structure is malformed")
+	ErrHeicMetadata   = errors.New("metadata of a heic image cannot be removed")
+)
+
+// keptPngChunks are the chunks that are needed to render a png, every other ancillary chunk such as
+// text, time and exif is dropped.
+var keptPngChunks = map[string]bool{
+	"IHDR": true, "PLTE": true, "IDAT": true, "IEND": true, "tRNS": true,
+	"gAMA": true, "cHRM": true, "sRGB": true, "iCCP": true, "sBIT": true, "pHYs": true,
+	"acTL": true, "fcTL": true, "fdAT": true,
+}
+
+// MetadataScan is what Sanitize removes from a file, found while the file is streamed.
+type MetadataScan struct {
+	// Stripped is whether metadata or data appended after the image is removed from the file.
+	Stripped bool
+	// Orientation is the exif orientation that has to be applied to the pixels, it is 1 for an upright image.
+	Orientation int
+	// Size is the size of the file without its metadata.
+	Size int64
+}
+
+// MetadataScanner is written a file that is streamed somewhere else and finds its metadata without keeping
+// the file in memory.
+type MetadataScanner interface {
+	io.Writer
+	// Close ends the file and returns what Sanitize would remove from it.
+	Close() (*MetadataScan, error)
+}
+
+// stripper copies an image to w without its metadata and returns its exif orientation and whether anything
+// was removed. size is the size of the result, which the webp header starts with, so a webp image has to be
+// scanned before it is stripped.
+type stripper func(w io.Writer, r io.Reader, size int64) (int, bool, error)
+
+var strippers = map[string]stripper{
+	"image/jpeg": stripJpeg,
+	"image/png":  stripPng,
+	"image/webp": stripWebp,
+	"image/heic": stripHeic,
+}
+
+// Sanitize removes the location, device and other metadata of JPEG, PNG, WebP and HEIC images and applies the
+// exif orientation to the pixels. Images without an orientation to apply keep their encoded data, only the
+// metadata is cut out of them, so nothing is lost to re-encoding. There is no webp encoder, so a WebP image with
+// an orientation to apply is converted to PNG. The metadata items of a HEIC image are zeroed instead of cut out,
+// see stripHeic. Other content types are returned unchanged.
+func (u *imageUtil) Sanitize(file []byte, contentType string) ([]byte, string, error) {
+	strip, ok := strippers[contentType]
+	if !ok {
+		return file, contentType, nil
+	}
+
+	var size byteCounter
+	orientation, stripped, err := strip(&size, bytes.NewReader(file), 0)
+	if err != nil {
+		return nil, "", err
+	}
+	if orientation != 1 {
+		return orientImage(file, contentType, orientation)
+	}
+	if !stripped {
+		return file, contentType, nil
+	}
+
+	var buf bytes.Buffer
+	_, _, err = strip(&buf, bytes.NewReader(file), int64(size))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), contentType, nil
+}
+
+// ScanMetadata returns the scanner of a file that is sanitized with StripMetadata after it is stored.
+func (u *imageUtil) ScanMetadata(contentType string) (MetadataScanner, error) {
+	strip, ok := strippers[contentType]
+	if !ok {
+		strip = keepFile
+	}
+
+	reader, writer := io.Pipe()
+	scanner := &metadataScanner{writer: writer, done: make(chan struct{})}
+	go func() {
+		defer close(scanner.done)
+
+		var size byteCounter
+		orientation, stripped, err := strip(&size, reader, 0)
+		// the rest of the file is read, so writing it never blocks after the structure ended or was malformed
+		_, _ = io.Copy(io.Discard, reader)
+
+		if err != nil {
+			scanner.err = err
+			return
+		}
+		scanner.scan = &MetadataScan{Stripped: stripped, Orientation: orientation, Size: int64(size)}
+	}()
+
+	return scanner, nil
+}
+
+// StripMetadata reads the file without the metadata that the scan found in it, the orientation is not applied
+// as that needs the whole image to be decoded. The caller must close the reader, which stops the stripping.
+func (u *imageUtil) StripMetadata(r io.Reader, contentType string, scan *MetadataScan) io.ReadCloser {
+	strip, ok := strippers[contentType]
+	if !ok {
+		strip = keepFile
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		_, _, err := strip(writer, r, scan.Size)
+		writer.CloseWithError(err)
+	}()
+
+	return reader
+}
+
+type metadataScanner struct {
+	writer *io.PipeWriter
+	done   chan struct{}
+	scan   *MetadataScan
+	err    error
+}
+
+func (s *metadataScanner) Write(p []byte) (int, error) {
+	return s.writer.Write(p)
+}
+
+func (s *metadataScanner) Close() (*MetadataScan, error) {
+	s.writer.Close()
+	<-s.done
+
+	return s.scan, s.err
+}
+
+func keepFile(w io.Writer, r io.Reader, _ int64) (int, bool, error) {
+	_, err := io.Copy(w, r)
+	return 1, false, err
+}
+
+func stripJpeg(w io.Writer, r io.Reader, _ int64) (int, bool, error) {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+
+	var soi [2]byte
+	_, err := io.ReadFull(reader, soi[:])
+	if err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return 1, false, malformed(err)
+	}
+	_, _ = writer.Write(soi[:])
+
+	orientation, stripped := 1, false
+	for {
+		var header [4]byte
+		_, err = io.ReadFull(reader, header[:2])
+		if err != nil || header[0] != 0xFF {
+			return 1, false, malformed(err)
+		}
+
+		marker := header[1]
+		// the entropy coded data follows the start of scan, it is copied up to the end of image marker which
+		// cannot appear inside of it, anything appended after it like the extra images of an mpo is dropped
+		if marker == 0xDA {
+			_, _ = writer.Write(header[:2])
+			err = copyEntropyData(writer, reader)
+			if err != nil {
+				return 1, false, malformed(err)
+			}
+
+			appended, err := io.Copy(io.Discard, reader)
+			if err != nil {
+				return 1, false, err
+			}
+
+			return orientation, stripped || appended > 0, writer.Flush()
+		}
+
+		_, err = io.ReadFull(reader, header[2:])
+		if err != nil {
+			return 1, false, malformed(err)
+		}
+		length := int(binary.BigEndian.Uint16(header[2:]))
+		if length < 2 {
+			return 1, false, ErrMalformedImage
+		}
+		segment := make([]byte, 2+length)
+		copy(segment, header[:])
+		_, err = io.ReadFull(reader, segment[4:])
+		if err != nil {
+			return 1, false, malformed(err)
+		}
+
+		switch {
+		case marker == 0xE1:
+			if bytes.HasPrefix(segment[4:], []byte("Exif\x00\x00")) {
+				orientation = exifOrientation(segment[10:])
+			}
+			stripped = true
+			continue
+		// app0 (jfif), the icc profile in app2 and app14 (adobe color transform) change how the image is decoded
+		case marker == 0xE2 && bytes.HasPrefix(segment[4:], []byte("ICC_PROFILE\x00")):
+		case marker > 0xE0 && marker <= 0xEF && marker != 0xEE:
+			stripped = true
+			continue
+		case marker == 0xFE:
+			stripped = true
+			continue
+		}
+
+		_, _ = writer.Write(segment)
+	}
+}
+
+// copyEntropyData copies the scan up to and including the end of image marker.
+func copyEntropyData(w *bufio.Writer, r *bufio.Reader) error {
+	for {
+		data, err := r.ReadSlice(0xFF)
+		_, _ = w.Write(data)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		next, err := r.Peek(1)
+		if err != nil {
+			return err
+		}
+		if next[0] == 0xD9 {
+			_, _ = r.Discard(1)
+			return w.WriteByte(0xD9)
+		}
+	}
+}
+
+func stripPng(w io.Writer, r io.Reader, _ int64) (int, bool, error) {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+
+	signature := []byte("\x89PNG\r\n\x1a\n")
+	header := make([]byte, len(signature))
+	_, err := io.ReadFull(reader, header)
+	if err != nil || !bytes.Equal(header, signature) {
+		return 1, false, malformed(err)
+	}
+	_, _ = writer.Write(signature)
+
+	orientation, stripped := 1, false
+	for {
+		var chunk [8]byte
+		_, err = io.ReadFull(reader, chunk[:])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 1, false, malformed(err)
+		}
+
+		length := int64(binary.BigEndian.Uint32(chunk[:4]))
+		chunkType := string(chunk[4:])
+		switch {
+		case chunkType == "eXIf":
+			data, err := readChunk(reader, length)
+			if err != nil {
+				return 1, false, err
+			}
+			orientation = exifOrientation(data)
+			_, err = io.CopyN(io.Discard, reader, 4)
+			if err != nil {
+				return 1, false, malformed(err)
+			}
+			stripped = true
+		case keptPngChunks[chunkType]:
+			_, _ = writer.Write(chunk[:])
+			// the data is followed by its crc
+			_, err = io.CopyN(writer, reader, length+4)
+			if err != nil {
+				return 1, false, malformed(err)
+			}
+		default:
+			_, err = io.CopyN(io.Discard, reader, length+4)
+			if err != nil {
+				return 1, false, malformed(err)
+			}
+			stripped = true
+		}
+
+		if chunkType == "IEND" {
+			appended, err := io.Copy(io.Discard, reader)
+			if err != nil {
+				return 1, false, err
+			}
+			stripped = stripped || appended > 0
+			break
+		}
+	}
+
+	return orientation, stripped, writer.Flush()
+}
+
+func stripWebp(w io.Writer, r io.Reader, size int64) (int, bool, error) {
+	reader := bufio.NewReader(r)
+	var written byteCounter
+	writer := bufio.NewWriter(io.MultiWriter(w, &written))
+
+	var header [12]byte
+	_, err := io.ReadFull(reader, header[:])
+	if err != nil || string(header[:4]) != "RIFF" || string(header[8:]) != "WEBP" {
+		return 1, false, malformed(err)
+	}
+	declared := int64(binary.LittleEndian.Uint32(header[4:8])) + 8
+	binary.LittleEndian.PutUint32(header[4:8], uint32(size-8))
+	_, _ = writer.Write(header[:])
+
+	orientation, stripped := 1, false
+	for {
+		var chunk [8]byte
+		_, err = io.ReadFull(reader, chunk[:])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 1, false, malformed(err)
+		}
+
+		length := int64(binary.LittleEndian.Uint32(chunk[4:]))
+		// chunks are padded to an even size
+		padded := length + length%2
+		switch string(chunk[:4]) {
+		case "EXIF":
+			data, err := readChunk(reader, padded)
+			if err != nil {
+				return 1, false, err
+			}
+			orientation = exifOrientation(bytes.TrimPrefix(data[:length], []byte("Exif\x00\x00")))
+			stripped = true
+		case "XMP ":
+			_, err = io.CopyN(io.Discard, reader, padded)
+			if err != nil {
+				return 1, false, malformed(err)
+			}
+			stripped = true
+		case "VP8X":
+			if length < 1 {
+				return 1, false, ErrMalformedImage
+			}
+			data, err := readChunk(reader, padded)
+			if err != nil {
+				return 1, false, err
+			}
+			// clear the exif and xmp flags
+			if data[0]&(0x08|0x04) != 0 {
+				data[0] &^= 0x08 | 0x04
+				stripped = true
+			}
+			_, _ = writer.Write(chunk[:])
+			_, _ = writer.Write(data)
+		default:
+			_, _ = writer.Write(chunk[:])
+			_, err = io.CopyN(writer, reader, padded)
+			if err != nil {
+				return 1, false, malformed(err)
+			}
+		}
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return 1, false, err
+	}
+
+	// the size in the header is rewritten, also when it did not match the file
+	return orientation, stripped || int64(written) != declared, nil
+}
+
+// stripHeic zeroes the data of the exif and xmp items of a HEIF file in place. The items are located by offsets
+// into the file, which stay valid as nothing is cut out, and their entries are kept so that the file still lists
+// them. The orientation of a HEIF image is a property of the image that decoders apply, not an exif tag, so it
+// is left as it is. The items are found in the meta box, a file whose metadata comes before its meta box or is
+// located relative to another item is rejected with ErrHeicMetadata.
+func stripHeic(w io.Writer, r io.Reader, _ int64) (int, bool, error) {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+
+	var pos int64
+	var ranges []byteRange
+	for {
+		header, boxType, size, err := readBoxHeader(reader)
+		if err == io.EOF {
+			return 1, false, writer.Flush()
+		}
+		if err != nil {
+			return 1, false, malformed(err)
+		}
+		_, _ = writer.Write(header)
+
+		// a box without a size runs to the end of the file
+		if size == 0 {
+			_, err = io.Copy(writer, reader)
+			if err != nil {
+				return 1, false, err
+			}
+
+			return 1, false, writer.Flush()
+		}
+		if size < int64(len(header)) {
+			return 1, false, ErrMalformedImage
+		}
+
+		if boxType != "meta" {
+			_, err = io.CopyN(writer, reader, size-int64(len(header)))
+			if err != nil {
+				return 1, false, malformed(err)
+			}
+			pos += size
+
+			continue
+		}
+
+		start := pos + int64(len(header))
+		data, err := readChunk(reader, size-int64(len(header)))
+		if err != nil {
+			return 1, false, err
+		}
+		ranges, err = heicMetadataRanges(data, start)
+		if err != nil {
+			return 1, false, err
+		}
+		for _, rng := range ranges {
+			if rng.start < start {
+				return 1, false, ErrHeicMetadata
+			}
+		}
+
+		zeroRanges(data, start, ranges)
+		_, _ = writer.Write(data)
+		pos += size
+		break
+	}
+
+	// the item data follows the meta box, usually in the mdat box
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := reader.Read(buf)
+		zeroRanges(buf[:n], pos, ranges)
+		_, _ = writer.Write(buf[:n])
+		pos += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 1, false, err
+		}
+	}
+
+	return 1, len(ranges) > 0, writer.Flush()
+}
+
+// byteRange is the range of the file from start up to end.
+type byteRange struct {
+	start, end int64
+}
+
+// zeroRanges zeroes the bytes of the ranges in the part of the file that starts at offset.
+func zeroRanges(data []byte, offset int64, ranges []byteRange) {
+	for _, rng := range ranges {
+		from := max(rng.start-offset, 0)
+		to := min(rng.end-offset, int64(len(data)))
+		if from < to {
+			clear(data[from:to])
+		}
+	}
+}
+
+// readBoxHeader reads the size and the type of an ISO base media file box, the size is 0 for a box that runs to
+// the end of the file.
+func readBoxHeader(r io.Reader) ([]byte, string, int64, error) {
+	header := make([]byte, 8, 16)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	size := int64(binary.BigEndian.Uint32(header[:4]))
+	if size == 1 {
+		header = header[:16]
+		_, err = io.ReadFull(r, header[8:])
+		if err != nil {
+			return nil, "", 0, malformed(err)
+		}
+		size = int64(binary.BigEndian.Uint64(header[8:]))
+	}
+
+	return header, string(header[4:8]), size, nil
+}
+
+// heicMetadataRanges finds the exif and xmp items in the item info (iinf) box of the meta box and returns the
+// ranges of the file that the item location (iloc) box puts them at. start is the offset of the meta box data in
+// the file, the data of an item can be inside of its item data (idat) box.
+func heicMetadataRanges(meta []byte, start int64) ([]byteRange, error) {
+	if len(meta) < 4 {
+		return nil, ErrMalformedImage
+	}
+
+	var iinf, iloc []byte
+	idatStart, idatEnd := int64(-1), int64(-1)
+	err := eachBox(meta[4:], func(boxType string, body []byte, offset int) {
+		switch boxType {
+		case "iinf":
+			iinf = body
+		case "iloc":
+			iloc = body
+		case "idat":
+			idatStart = start + 4 + int64(offset)
+			idatEnd = idatStart + int64(len(body))
+		}
+	})
+	if err != nil || iinf == nil || iloc == nil {
+		return nil, err
+	}
+
+	items, err := heicMetadataItems(iinf)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	b := &boxData{data: iloc}
+	version := b.uint(1)
+	b.uint(3)
+	sizes := b.uint(2)
+	offsetSize, lengthSize, baseOffsetSize := int(sizes>>12), int(sizes>>8&0xF), int(sizes>>4&0xF)
+	indexSize := 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0xF)
+	}
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+
+	var ranges []byteRange
+	count := b.uint(idSize)
+	for i := uint64(0); i < count && b.err == nil; i++ {
+		id := b.uint(idSize)
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			method = b.uint(2) & 0xF
+		}
+		b.uint(2)
+		base := int64(b.uint(baseOffsetSize))
+		extents := b.uint(2)
+		for j := uint64(0); j < extents && b.err == nil; j++ {
+			b.uint(indexSize)
+			offset := base + int64(b.uint(offsetSize))
+			length := int64(b.uint(lengthSize))
+			if !items[id] {
+				continue
+			}
+
+			rng := byteRange{start: offset, end: offset + length}
+			switch {
+			case method == 0 && length == 0:
+				rng.end = math.MaxInt64
+			case method == 1 && idatStart >= 0:
+				rng = byteRange{start: idatStart + offset, end: idatStart + offset + length}
+				if length == 0 {
+					rng.end = idatEnd
+				}
+			case method != 0:
+				return nil, ErrHeicMetadata
+			}
+			ranges = append(ranges, rng)
+		}
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return ranges, nil
+}
+
+// heicMetadataItems returns the ids of the exif items and of the mime items that hold xmp.
+func heicMetadataItems(iinf []byte) (map[uint64]bool, error) {
+	if len(iinf) < 4 {
+		return nil, ErrMalformedImage
+	}
+	countSize := 2
+	if iinf[0] != 0 {
+		countSize = 4
+	}
+	if len(iinf) < 4+countSize {
+		return nil, ErrMalformedImage
+	}
+
+	items := map[uint64]bool{}
+	err := eachBox(iinf[4+countSize:], func(boxType string, body []byte, _ int) {
+		// only the entries of version 2 and 3 have an item type
+		if boxType != "infe" || len(body) < 1 || body[0] < 2 {
+			return
+		}
+
+		b := &boxData{data: body}
+		version := b.uint(1)
+		b.uint(3)
+		id := b.uint(2)
+		if version == 3 {
+			id = id<<16 | b.uint(2)
+		}
+		b.uint(2)
+		itemType := string(b.bytes(4))
+		b.string()
+		if b.err != nil {
+			return
+		}
+
+		if itemType == "Exif" || (itemType == "mime" && b.string() == "application/rdf+xml") {
+			items[id] = true
+		}
+	})
+
+	return items, err
+}
+
+// eachBox calls fn with the type, the body and the offset of the body of the boxes that data is made of.
+func eachBox(data []byte, fn func(boxType string, body []byte, offset int)) error {
+	for pos := 0; pos < len(data); {
+		if len(data)-pos < 8 {
+			return ErrMalformedImage
+		}
+
+		size := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		headerSize := 8
+		switch size {
+		case 0:
+			size = len(data) - pos
+		case 1:
+			if len(data)-pos < 16 {
+				return ErrMalformedImage
+			}
+			large := binary.BigEndian.Uint64(data[pos+8 : pos+16])
+			if large > uint64(len(data)-pos) {
+				return ErrMalformedImage
+			}
+			size, headerSize = int(large), 16
+		}
+		if size < headerSize || size > len(data)-pos {
+			return ErrMalformedImage
+		}
+
+		fn(string(data[pos+4:pos+8]), data[pos+headerSize:pos+size], pos+headerSize)
+		pos += size
+	}
+
+	return nil
+}
+
+// boxData reads the big endian fields of a box, err is ErrMalformedImage once a field runs past its end.
+type boxData struct {
+	data []byte
+	err  error
+}
+
+// uint reads an unsigned integer of size bytes, a size of 0 reads nothing and is 0.
+func (b *boxData) uint(size int) uint64 {
+	var value uint64
+	for _, c := range b.bytes(size) {
+		value = value<<8 | uint64(c)
+	}
+
+	return value
+}
+
+func (b *boxData) bytes(size int) []byte {
+	if b.err != nil || size > len(b.data) {
+		b.err = ErrMalformedImage
+		return nil
+	}
+
+	value := b.data[:size]
+	b.data = b.data[size:]
+
+	return value
+}
+
+// string reads a null terminated string.
+func (b *boxData) string() string {
+	end := bytes.IndexByte(b.data, 0)
+	if b.err != nil || end < 0 {
+		b.err = ErrMalformedImage
+		return ""
+	}
+
+	value := string(b.data[:end])
+	b.data = b.data[end+1:]
+
+	return value
+}
+
+// readChunk reads the data of a chunk, the buffer grows with what is read so that a made up length cannot
+// allocate more than the file.
+func readChunk(r io.Reader, length int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, length))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != length {
+		return nil, ErrMalformedImage
+	}
+
+	return data, nil
+}
+
+// malformed is the error of a file that ends in the middle of its structure, other errors of the reader are
+// returned as they are.
+func malformed(err error) error {
+	if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrMalformedImage
+	}
+
+	return err
+}
+
+// orientImage decodes the image to apply the orientation to its pixels and encodes it again.
+func orientImage(file []byte, contentType string, orientation int) ([]byte, string, error) {
+	switch contentType {
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(file))
+		if err != nil {
+			return nil, "", ErrMalformedImage
+		}
+
+		var buf bytes.Buffer
+		err = jpeg.Encode(&buf, orient(img, orientation), &jpeg.Options{Quality: sanitizedJpegQuality})
+		if err != nil {
+			return nil, "", err
+		}
+
+		return buf.Bytes(), "image/jpeg", nil
+	case "image/png":
+		img, err := png.Decode(bytes.NewReader(file))
+		if err != nil {
+			return nil, "", ErrMalformedImage
+		}
+
+		return encodePng(orient(img, orientation))
+	default:
+		img, err := webp.Decode(bytes.NewReader(file))
+		if err != nil {
+			return nil, "", ErrMalformedImage
+		}
+
+		return encodePng(orient(img, orientation))
+	}
+}
+
+// byteCounter counts the bytes written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+func encodePng(img image.Image) ([]byte, string, error) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), "image/png", nil
+}
+
+// exifOrientation reads the orientation tag of the first image file directory of the tiff structured exif
+// data, it is 1 when the tag is missing or invalid.
+func exifOrientation(data []byte) int {
+	if len(data) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(data[4:8]))
+	if offset < 8 || offset+2 > len(data) {
+		return 1
+	}
+
+	count := int(order.Uint16(data[offset : offset+2]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(data) {
+			return 1
+		}
+
+		if order.Uint16(data[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(data[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+// orient transforms the image so it is displayed upright without its exif orientation.
+func orient(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	w, h := bounds.Dx(), bounds.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+
+			dst.SetRGBA(x, y, src.RGBAAt(sx, sy))
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,293 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/image/webp"
+)
+
+type MetadataUtilTest struct {
+	suite.Suite
+}
+
+func TestMetadataUtil(t *testing.T) {
+	suite.Run(t, new(MetadataUtilTest))
+}
+
+func (t *MetadataUtilTest) fixture(name string) []byte {
+	file, err := os.ReadFile(filepath.Join("testdata", name))
+	assert.Nil(t.T(), err)
+
+	return file
+}
+
+func (t *MetadataUtilTest) assertNoMetadata(file []byte) {
+	for _, metadata := range []string{"Exif", "Camera", "GPSLatitude", "shot at home", "volunteer", "eXIf", "XMP "} {
+		assert.NotContains(t.T(), string(file), metadata)
+	}
+}
+
+func (t *MetadataUtilTest) assertHalves(img image.Image, vertical bool) {
+	bounds := img.Bounds()
+	first := img.At(bounds.Min.X+2, bounds.Min.Y+2)
+	last := img.At(bounds.Max.X-3, bounds.Max.Y-3)
+	if vertical {
+		// the red left half of the original has been turned to the top
+		first = img.At(bounds.Min.X+bounds.Dx()/2, bounds.Min.Y+2)
+		last = img.At(bounds.Min.X+bounds.Dx()/2, bounds.Max.Y-3)
+	}
+
+	r, _, b, _ := first.RGBA()
+	assert.Greater(t.T(), r, b)
+	r, _, b, _ = last.RGBA()
+	assert.Greater(t.T(), b, r)
+}
+
+func (t *MetadataUtilTest) TestSanitizeJpeg() {
+	file := t.fixture("gps.jpg")
+
+	sanitized, contentType, err := NewImageUtil().Sanitize(file, "image/jpeg")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "image/jpeg", contentType)
+	t.assertNoMetadata(sanitized)
+
+	// without an orientation to apply the encoded image is kept as it is
+	expected, _, err := image.Decode(bytes.NewReader(file))
+	assert.Nil(t.T(), err)
+	actual, _, err := image.Decode(bytes.NewReader(sanitized))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), expected, actual)
+}
+
+func (t *MetadataUtilTest) TestSanitizeJpegOrientation() {
+	sanitized, contentType, err := NewImageUtil().Sanitize(t.fixture("gps_orientation_6.jpg"), "image/jpeg")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "image/jpeg", contentType)
+	t.assertNoMetadata(sanitized)
+
+	img, _, err := image.Decode(bytes.NewReader(sanitized))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 16, img.Bounds().Dx())
+	assert.Equal(t.T(), 32, img.Bounds().Dy())
+	t.assertHalves(img, true)
+}
+
+func (t *MetadataUtilTest) TestSanitizePngOrientation() {
+	sanitized, contentType, err := NewImageUtil().Sanitize(t.fixture("text_orientation_3.png"), "image/png")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "image/png", contentType)
+	t.assertNoMetadata(sanitized)
+
+	img, _, err := image.Decode(bytes.NewReader(sanitized))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), 32, img.Bounds().Dx())
+	assert.Equal(t.T(), 16, img.Bounds().Dy())
+	// rotated by 180 degrees the blue half is on the left
+	r, _, b, _ := img.At(2, 2).RGBA()
+	assert.Greater(t.T(), b, r)
+}
+
+func (t *MetadataUtilTest) TestSanitizeWebp() {
+	sanitized, contentType, err := NewImageUtil().Sanitize(t.fixture("gps_xmp.webp"), "image/webp")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "image/webp", contentType)
+	t.assertNoMetadata(sanitized)
+	// the exif and xmp flags of the extended header are cleared
+	assert.Equal(t.T(), byte(0), sanitized[20]&(0x08|0x04))
+
+	_, format, err := image.Decode(bytes.NewReader(sanitized))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "webp", format)
+
+	// the lossless image data is kept as it is instead of being re-encoded
+	file := t.fixture("gps_xmp.webp")
+	vp8l := bytes.Index(file, []byte("VP8L"))
+	length := int(binary.LittleEndian.Uint32(file[vp8l+4 : vp8l+8]))
+	assert.Contains(t.T(), string(sanitized), string(file[vp8l:vp8l+8+length]))
+}
+
+func (t *MetadataUtilTest) TestSanitizeWebpOrientation() {
+	// the orientation tag of the exif chunk is turned from 1 to 6
+	file := t.fixture("gps_xmp.webp")
+	tag := []byte{0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00}
+	assert.Equal(t.T(), 1, bytes.Count(file, tag))
+	file = bytes.Replace(file, tag, []byte{0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x00}, 1)
+
+	sanitized, contentType, err := NewImageUtil().Sanitize(file, "image/webp")
+
+	// there is no webp encoder, so the oriented image is converted to png
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "image/png", contentType)
+	t.assertNoMetadata(sanitized)
+
+	original, err := webp.Decode(bytes.NewReader(t.fixture("gps_xmp.webp")))
+	assert.Nil(t.T(), err)
+	img, format, err := image.Decode(bytes.NewReader(sanitized))
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "png", format)
+	assert.Equal(t.T(), original.Bounds().Dx(), img.Bounds().Dy())
+	assert.Equal(t.T(), original.Bounds().Dy(), img.Bounds().Dx())
+}
+
+func (t *MetadataUtilTest) TestSanitizeHeic() {
+	file := t.fixture("gps_xmp.heic")
+
+	sanitized, contentType, err := NewImageUtil().Sanitize(file, "image/heic")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "image/heic", contentType)
+	for _, metadata := range []string{"Camera", "GPSLatitude", "shot at home", "volunteer"} {
+		assert.NotContains(t.T(), string(sanitized), metadata)
+	}
+
+	// the items are zeroed in place, so the offsets of the item locations still point at the image data
+	assert.Len(t.T(), sanitized, len(file))
+	data := bytes.Index(file, []byte("HEVC image data"))
+	assert.Equal(t.T(), data, bytes.Index(sanitized, []byte("HEVC image data")))
+	exif := bytes.Index(file, []byte("\x00\x00\x00\x06Exif"))
+	assert.Equal(t.T(), file[:exif], sanitized[:exif])
+	assert.Equal(t.T(), make([]byte, len(file)-exif), sanitized[exif:])
+}
+
+func (t *MetadataUtilTest) TestSanitizeHeicWithoutMetadata() {
+	file := []byte("\x00\x00\x00\x18ftypmif1\x00\x00\x00\x00mif1heic\x00\x00\x00\x0cmdatdata")
+
+	sanitized, contentType, err := NewImageUtil().Sanitize(file, "image/heic")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "image/heic", contentType)
+	assert.Equal(t.T(), file, sanitized)
+}
+
+func (t *MetadataUtilTest) TestSanitizeHeicMetadataBeforeMeta() {
+	file := t.fixture("gps_xmp.heic")
+	ftyp, mdat := file[:24], bytes.Index(file, []byte("mdat"))-4
+	meta := bytes.Clone(file[24:mdat])
+	// the item data is moved in front of the meta box, the offsets of the items are moved with it
+	for _, item := range []string{"\x00\x00\x00\x10HEVC", "\x00\x00\x00\x06Exif", "<x:xmpmeta>"} {
+		offset := uint32(bytes.Index(file, []byte(item)))
+		meta = bytes.Replace(meta, binary.BigEndian.AppendUint32(nil, offset), binary.BigEndian.AppendUint32(nil, offset-uint32(len(meta))), 1)
+	}
+	moved := append(append(bytes.Clone(ftyp), file[mdat:]...), meta...)
+
+	sanitized, _, err := NewImageUtil().Sanitize(moved, "image/heic")
+
+	assert.Nil(t.T(), sanitized)
+	assert.Equal(t.T(), ErrHeicMetadata, err)
+}
+
+func (t *MetadataUtilTest) TestSanitizeHeicMalformed() {
+	file := t.fixture("gps_xmp.heic")
+
+	sanitized, _, err := NewImageUtil().Sanitize(file[:100], "image/heic")
+
+	assert.Nil(t.T(), sanitized)
+	assert.Equal(t.T(), ErrMalformedImage, err)
+}
+
+func (t *MetadataUtilTest) TestSanitizeOtherType() {
+	file := []byte("GIF89a")
+
+	sanitized, contentType, err := NewImageUtil().Sanitize(file, "image/gif")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), "image/gif", contentType)
+	assert.Equal(t.T(), file, sanitized)
+}
+
+func (t *MetadataUtilTest) TestSanitizeMalformed() {
+	sanitized, _, err := NewImageUtil().Sanitize([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF}, "image/jpeg")
+
+	assert.Nil(t.T(), sanitized)
+	assert.Equal(t.T(), ErrMalformedImage, err)
+}
+
+// scan writes the file to a scanner in small pieces like an upload stream does.
+func (t *MetadataUtilTest) scan(file []byte, contentType string) (*MetadataScan, error) {
+	scanner, err := NewImageUtil().ScanMetadata(contentType)
+	assert.Nil(t.T(), err)
+
+	for len(file) > 0 {
+		n := min(len(file), 100)
+		_, err = scanner.Write(file[:n])
+		assert.Nil(t.T(), err)
+		file = file[n:]
+	}
+
+	return scanner.Close()
+}
+
+func (t *MetadataUtilTest) TestStripMetadata() {
+	for _, test := range []struct {
+		fixture     string
+		contentType string
+	}{
+		{fixture: "gps.jpg", contentType: "image/jpeg"},
+		{fixture: "gps_xmp.webp", contentType: "image/webp"},
+		{fixture: "gps_xmp.heic", contentType: "image/heic"},
+	} {
+		t.Run(test.fixture, func() {
+			file := t.fixture(test.fixture)
+			sanitized, _, err := NewImageUtil().Sanitize(file, test.contentType)
+			assert.Nil(t.T(), err)
+
+			scan, err := t.scan(file, test.contentType)
+
+			assert.Nil(t.T(), err)
+			assert.Equal(t.T(), &MetadataScan{Stripped: true, Orientation: 1, Size: int64(len(sanitized))}, scan)
+
+			reader := NewImageUtil().StripMetadata(bytes.NewReader(file), test.contentType, scan)
+			stripped, err := io.ReadAll(reader)
+			assert.Nil(t.T(), err)
+			assert.Nil(t.T(), reader.Close())
+			assert.Equal(t.T(), sanitized, stripped)
+		})
+	}
+}
+
+func (t *MetadataUtilTest) TestScanMetadataOrientation() {
+	scan, err := t.scan(t.fixture("gps_orientation_6.jpg"), "image/jpeg")
+
+	assert.Nil(t.T(), err)
+	assert.True(t.T(), scan.Stripped)
+	assert.Equal(t.T(), 6, scan.Orientation)
+}
+
+func (t *MetadataUtilTest) TestScanMetadataWithoutMetadata() {
+	sanitized, _, err := NewImageUtil().Sanitize(t.fixture("gps.jpg"), "image/jpeg")
+	assert.Nil(t.T(), err)
+
+	scan, err := t.scan(sanitized, "image/jpeg")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), &MetadataScan{Orientation: 1, Size: int64(len(sanitized))}, scan)
+}
+
+func (t *MetadataUtilTest) TestScanMetadataOtherType() {
+	scan, err := t.scan([]byte("GIF89a"), "image/gif")
+
+	assert.Nil(t.T(), err)
+	assert.Equal(t.T(), &MetadataScan{Orientation: 1, Size: 6}, scan)
+}
+
+func (t *MetadataUtilTest) TestScanMetadataMalformed() {
+	file := t.fixture("gps.jpg")
+
+	// the scanner keeps reading the file after the structure is found to be malformed
+	scan, err := t.scan(file[:len(file)/2], "image/jpeg")
+
+	assert.Nil(t.T(), scan)
+	assert.Equal(t.T(), ErrMalformedImage, err)
+}
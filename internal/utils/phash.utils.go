@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+
+	"golang.org/x/image/draw"
+)
+
+// PerceptualHash computes the difference hash of the image, the image is shrunk to 9x8 grey pixels and each
+// bit tells whether a pixel is darker than its right neighbour. Resized and re-compressed copies of an image
+// have hashes that differ in only a few bits.
+func (u *imageUtil) PerceptualHash(src image.Image) string {
+	grey := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.CatmullRom.Scale(grey, grey.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if grey.GrayAt(x, y).Y < grey.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+
+	return fmt.Sprintf("%016x", hash)
+}
+
+// HammingDistance counts the bits that differ between two perceptual hashes, it is false when either of
+// them is not a hash.
+func HammingDistance(a string, b string) (int, bool) {
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return bits.OnesCount64(x ^ y), true
+}
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// placeholderSize is the longer side of the thumbnail the placeholders are computed from, the BlurHash only
+// keeps a few low frequencies so a larger thumbnail would not change it.
+const placeholderSize = 32
+
+const base83Characters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+type ImagePlaceholder struct {
+	BlurHash      string
+	DominantColor string
+}
+
+// Placeholder computes the BlurHash and the dominant colour of the image, which clients show while the image
+// loads. Transparent pixels are treated as white.
+func (u *imageUtil) Placeholder(src image.Image) *ImagePlaceholder {
+	bounds := src.Bounds()
+	width, height := placeholderSize, placeholderSize
+	if bounds.Dx() > bounds.Dy() {
+		height = max(1, bounds.Dy()*placeholderSize/bounds.Dx())
+	} else {
+		width = max(1, bounds.Dx()*placeholderSize/bounds.Dy())
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(thumbnail, thumbnail.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.BiLinear.Scale(thumbnail, thumbnail.Bounds(), src, bounds, draw.Over, nil)
+
+	componentsX, componentsY := 4, 3
+	if height > width {
+		componentsX, componentsY = 3, 4
+	}
+
+	return &ImagePlaceholder{
+		BlurHash:      blurHash(thumbnail, componentsX, componentsY),
+		DominantColor: dominantColor(thumbnail),
+	}
+}
+
+// blurHash encodes the image as described in https://github.com/woltapp/blurhash, the DC component is the
+// average colour and the AC components are quantised relative to the largest of them.
+func blurHash(img *image.RGBA, componentsX int, componentsY int) string {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+
+	linear := make([][3]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := img.RGBAAt(x, y)
+			linear[y*width+x] = [3]float64{srgbToLinear(c.R), srgbToLinear(c.G), srgbToLinear(c.B)}
+		}
+	}
+
+	factors := make([][3]float64, 0, componentsX*componentsY)
+	for j := 0; j < componentsY; j++ {
+		for i := 0; i < componentsX; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+
+			var factor [3]float64
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := math.Cos(math.Pi*float64(i*x)/float64(width)) * math.Cos(math.Pi*float64(j*y)/float64(height))
+					for k := range factor {
+						factor[k] += basis * linear[y*width+x][k]
+					}
+				}
+			}
+
+			scale := normalisation / float64(width*height)
+			for k := range factor {
+				factor[k] *= scale
+			}
+			factors = append(factors, factor)
+		}
+	}
+
+	hash := encodeBase83((componentsX-1)+(componentsY-1)*9, 1)
+
+	maximumValue := 1.0
+	if len(factors) > 1 {
+		actualMaximum := 0.0
+		for _, factor := range factors[1:] {
+			for _, v := range factor {
+				actualMaximum = math.Max(actualMaximum, math.Abs(v))
+			}
+		}
+
+		quantisedMaximum := int(math.Max(0, math.Min(82, math.Floor(actualMaximum*166-0.5))))
+		maximumValue = float64(quantisedMaximum+1) / 166
+		hash += encodeBase83(quantisedMaximum, 1)
+	} else {
+		hash += encodeBase83(0, 1)
+	}
+
+	dc := factors[0]
+	hash += encodeBase83(linearToSrgb(dc[0])<<16|linearToSrgb(dc[1])<<8|linearToSrgb(dc[2]), 4)
+
+	for _, factor := range factors[1:] {
+		var value int
+		for _, v := range factor {
+			quantised := int(math.Max(0, math.Min(18, math.Floor(signPow(v/maximumValue, 0.5)*9+9.5))))
+			value = value*19 + quantised
+		}
+		hash += encodeBase83(value, 2)
+	}
+
+	return hash
+}
+
+// dominantColor returns the average colour of the most common bucket when each channel is reduced to 4 bits,
+// as a hex colour.
+func dominantColor(img *image.RGBA) string {
+	var counts [4096]int
+	var sums [4096][3]int
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			bucket := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			counts[bucket]++
+			sums[bucket][0] += int(c.R)
+			sums[bucket][1] += int(c.G)
+			sums[bucket][2] += int(c.B)
+		}
+	}
+
+	dominant := 0
+	for bucket, count := range counts {
+		if count > counts[dominant] {
+			dominant = bucket
+		}
+	}
+
+	count := max(1, counts[dominant])
+	c := color.RGBA{
+		R: uint8(sums[dominant][0] / count),
+		G: uint8(sums[dominant][1] / count),
+		B: uint8(sums[dominant][2] / count),
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func encodeBase83(value int, length int) string {
+	result := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		result[i] = base83Characters[value%83]
+		value /= 83
+	}
+
+	return string(result)
+}
+
+func srgbToLinear(value uint8) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSrgb(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(value float64, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
@@ -5,9 +5,13 @@
 package mock_bucket
 
 import (
+	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	bucket "github.com/isd-sgcu/johnjud-file/pkg/client/bucket"
 )
 
 // MockClient is a mock of Client interface.
@@ -33,6 +37,22 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// Copy mocks base method.
+func (m *MockClient) Copy(arg0, arg1 string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Copy", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Copy indicates an expected call of Copy.
+func (mr *MockClientMockRecorder) Copy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockClient)(nil).Copy), arg0, arg1)
+}
+
 // Delete mocks base method.
 func (m *MockClient) Delete(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -47,6 +67,95 @@ func (mr *MockClientMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClient)(nil).Delete), arg0)
 }
 
+// Get mocks base method.
+func (m *MockClient) Get(arg0 string) (*bucket.Object, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*bucket.Object)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockClientMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockClient)(nil).Get), arg0)
+}
+
+// Head mocks base method.
+func (m *MockClient) Head(arg0 string) (*bucket.ObjectInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Head", arg0)
+	ret0, _ := ret[0].(*bucket.ObjectInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Head indicates an expected call of Head.
+func (mr *MockClientMockRecorder) Head(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Head", reflect.TypeOf((*MockClient)(nil).Head), arg0)
+}
+
+// List mocks base method.
+func (m *MockClient) List(arg0 func(*bucket.ObjectInfo) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// List indicates an expected call of List.
+func (mr *MockClientMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockClient)(nil).List), arg0)
+}
+
+// ObjectUrl mocks base method.
+func (m *MockClient) ObjectUrl(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ObjectUrl", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ObjectUrl indicates an expected call of ObjectUrl.
+func (mr *MockClientMockRecorder) ObjectUrl(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObjectUrl", reflect.TypeOf((*MockClient)(nil).ObjectUrl), arg0)
+}
+
+// PresignDownload mocks base method.
+func (m *MockClient) PresignDownload(arg0 string, arg1 time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresignDownload", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresignDownload indicates an expected call of PresignDownload.
+func (mr *MockClientMockRecorder) PresignDownload(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresignDownload", reflect.TypeOf((*MockClient)(nil).PresignDownload), arg0, arg1)
+}
+
+// PresignUpload mocks base method.
+func (m *MockClient) PresignUpload(arg0, arg1 string, arg2 time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresignUpload", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresignUpload indicates an expected call of PresignUpload.
+func (mr *MockClientMockRecorder) PresignUpload(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresignUpload", reflect.TypeOf((*MockClient)(nil).PresignUpload), arg0, arg1, arg2)
+}
+
 // Upload mocks base method.
 func (m *MockClient) Upload(arg0 []byte, arg1 string) (string, string, error) {
 	m.ctrl.T.Helper()
@@ -62,3 +171,19 @@ func (mr *MockClientMockRecorder) Upload(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockClient)(nil).Upload), arg0, arg1)
 }
+
+// UploadStream mocks base method.
+func (m *MockClient) UploadStream(arg0 context.Context, arg1 io.Reader, arg2, arg3 string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadStream", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UploadStream indicates an expected call of UploadStream.
+func (mr *MockClientMockRecorder) UploadStream(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadStream", reflect.TypeOf((*MockClient)(nil).UploadStream), arg0, arg1, arg2, arg3)
+}
@@ -1,7 +1,10 @@
 package image
 
 import (
+	"time"
+
 	"github.com/isd-sgcu/johnjud-file/internal/model"
+	"github.com/isd-sgcu/johnjud-file/pkg/repository/image"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -9,6 +12,11 @@ type ImageRepositoryMock struct {
 	mock.Mock
 }
 
+// WithTx runs the unit of work on the mock itself, the calls inside it are expected like any other call.
+func (m *ImageRepositoryMock) WithTx(fn func(repository image.Repository) error) error {
+	return fn(m)
+}
+
 func (m *ImageRepositoryMock) FindOne(id string, image *model.Image) error {
 	args := m.Called(id, image)
 	if args.Get(0) != nil {
@@ -29,6 +37,108 @@ func (m *ImageRepositoryMock) FindByPetId(id string, image *[]*model.Image) erro
 	return args.Error(1)
 }
 
+func (m *ImageRepositoryMock) FindByIds(ids []string, image *[]*model.Image) error {
+	args := m.Called(ids, image)
+	if args.Get(0) != nil {
+		*image = *args.Get(0).(*[]*model.Image)
+		return nil
+	}
+
+	return args.Error(1)
+}
+
+func (m *ImageRepositoryMock) List(query *image.ListQuery, result *[]*model.Image) error {
+	args := m.Called(query, result)
+	if args.Get(0) != nil {
+		*result = *args.Get(0).(*[]*model.Image)
+		return nil
+	}
+
+	return args.Error(1)
+}
+
+func (m *ImageRepositoryMock) Count(filter *image.Filter, result *int64) error {
+	args := m.Called(filter, result)
+	if args.Get(0) != nil {
+		*result = *args.Get(0).(*int64)
+		return nil
+	}
+
+	return args.Error(1)
+}
+
+func (m *ImageRepositoryMock) FindByChecksum(checksum string, image *model.Image) error {
+	args := m.Called(checksum, image)
+	if args.Get(0) != nil {
+		*image = *args.Get(0).(*model.Image)
+		return nil
+	}
+
+	return args.Error(1)
+}
+
+func (m *ImageRepositoryMock) ReserveObject(checksum string, objectKey string) error {
+	args := m.Called(checksum, objectKey)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) ReleaseObject(checksum string) error {
+	args := m.Called(checksum)
+
+	return args.Error(0)
+}
+
+// DeleteUnreferencedObject runs fn unless the object is expected to be referenced or the call to fail.
+func (m *ImageRepositoryMock) DeleteUnreferencedObject(checksum string, fn func() error) (bool, error) {
+	args := m.Called(checksum)
+	if args.Bool(0) || args.Error(1) != nil {
+		return args.Bool(0), args.Error(1)
+	}
+
+	return false, fn()
+}
+
+func (m *ImageRepositoryMock) FindObjectKeys(result *[]string) error {
+	args := m.Called(result)
+	if args.Get(0) != nil {
+		*result = *args.Get(0).(*[]string)
+		return nil
+	}
+
+	return args.Error(1)
+}
+
+func (m *ImageRepositoryMock) AssignPet(petId string, ids []string) error {
+	args := m.Called(petId, ids)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) UnassignPet(ids []string) error {
+	args := m.Called(ids)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) MovePet(fromPetId string, toPetId string, ids []string) error {
+	args := m.Called(fromPetId, toPetId, ids)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) Reorder(petId string, ids []string) error {
+	args := m.Called(petId, ids)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) SetCover(petId string, id string) error {
+	args := m.Called(petId, id)
+
+	return args.Error(0)
+}
+
 func (m *ImageRepositoryMock) Create(image *model.Image) error {
 	args := m.Called(image)
 	if args.Get(0) != nil {
@@ -49,8 +159,84 @@ func (m *ImageRepositoryMock) Update(id string, image *model.Image) error {
 	return args.Error(1)
 }
 
+func (m *ImageRepositoryMock) ConfirmUpload(id string, image *model.Image) error {
+	args := m.Called(id, image)
+	if args.Get(0) != nil {
+		*image = *args.Get(0).(*model.Image)
+		return nil
+	}
+
+	return args.Error(1)
+}
+
 func (m *ImageRepositoryMock) Delete(id string) error {
 	args := m.Called(id)
 
 	return args.Error(0)
 }
+
+func (m *ImageRepositoryMock) DeleteUnassigned(id string, before time.Time) error {
+	args := m.Called(id, before)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) DeleteExpiredUpload(id string, before time.Time) error {
+	args := m.Called(id, before)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) Restore(id string, result *model.Image) error {
+	args := m.Called(id, result)
+	if args.Get(0) != nil {
+		*result = *args.Get(0).(*model.Image)
+		return nil
+	}
+
+	return args.Error(1)
+}
+
+func (m *ImageRepositoryMock) FindDeleted(before time.Time, limit int, result *[]*model.Image) error {
+	args := m.Called(before, limit, result)
+	if args.Get(0) != nil {
+		*result = *args.Get(0).(*[]*model.Image)
+		return nil
+	}
+
+	return args.Error(1)
+}
+
+func (m *ImageRepositoryMock) Purge(id string, before time.Time) error {
+	args := m.Called(id, before)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) CreateFailedCompensations(in []*model.FailedCompensation) error {
+	args := m.Called(in)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) FindFailedCompensations(limit int, result *[]*model.FailedCompensation) error {
+	args := m.Called(limit, result)
+	if args.Get(0) != nil {
+		*result = *args.Get(0).(*[]*model.FailedCompensation)
+		return nil
+	}
+
+	return args.Error(1)
+}
+
+func (m *ImageRepositoryMock) UpdateFailedCompensation(id string, in *model.FailedCompensation) error {
+	args := m.Called(id, in)
+
+	return args.Error(0)
+}
+
+func (m *ImageRepositoryMock) DeleteFailedCompensation(id string) error {
+	args := m.Called(id)
+
+	return args.Error(0)
+}
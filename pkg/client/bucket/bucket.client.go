@@ -1,16 +1,47 @@
 package bucket
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/isd-sgcu/johnjud-file/cfgldr"
 	"github.com/isd-sgcu/johnjud-file/client/bucket"
 )
 
+type Object = bucket.Object
+
+type ObjectInfo = bucket.ObjectInfo
+
+var ErrObjectNotFound = bucket.ErrObjectNotFound
+
 type Client interface {
 	Upload([]byte, string) (string, string, error)
+	UploadStream(context.Context, io.Reader, string, string) (string, string, error)
+	Copy(string, string) (string, string, error)
+	Get(string) (*Object, error)
+	Head(string) (*ObjectInfo, error)
+	PresignUpload(string, string, time.Duration) (string, error)
+	PresignDownload(string, time.Duration) (string, error)
+	ObjectUrl(string) (string, error)
 	Delete(string) error
+	List(func(*ObjectInfo) error) error
 }
 
 func NewClient(config cfgldr.S3, awsClient *s3.Client) Client {
 	return bucket.NewClient(config, awsClient)
 }
+
+func NewLocalClient(config cfgldr.Local) Client {
+	return bucket.NewLocalClient(config)
+}
+
+func NewMemoryClient() Client {
+	return bucket.NewMemoryClient()
+}
+
+func NewLocalFileHandler(config cfgldr.Local, maxSize int64) http.Handler {
+	return bucket.NewLocalFileHandler(config, maxSize)
+}
@@ -1,13 +1,74 @@
 package image
 
 import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isd-sgcu/johnjud-file/constant"
 	"github.com/isd-sgcu/johnjud-file/internal/model"
 )
 
+// ErrReorderMismatch is returned by Reorder when the ids are not exactly the images of the pet.
+var ErrReorderMismatch = errors.New("ids are not the images of the pet")
+
+// Filter narrows down the images of a listing, the zero value of a field does not filter.
+type Filter struct {
+	PetID         *uuid.UUID
+	Assigned      *bool
+	Status        constant.ImageStatus
+	ContentType   string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedBefore time.Time
+	MinSize       int64
+	MaxSize       int64
+}
+
+// Cursor is the position of the last image of the previous page.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ListQuery is a page of images sorted by their creation time and then their id.
+type ListQuery struct {
+	Filter    Filter
+	After     *Cursor
+	Ascending bool
+	Limit     int
+}
+
 type Repository interface {
+	// WithTx runs the unit of work in a transaction, everything it does through the given repository is
+	// rolled back when it returns an error.
+	WithTx(fn func(repository Repository) error) error
 	FindOne(id string, result *model.Image) error
 	FindByPetId(id string, result *[]*model.Image) error
+	FindByIds(ids []string, result *[]*model.Image) error
+	List(query *ListQuery, result *[]*model.Image) error
+	Count(filter *Filter, result *int64) error
+	FindByChecksum(checksum string, result *model.Image) error
+	ReserveObject(checksum string, objectKey string) error
+	ReleaseObject(checksum string) error
+	DeleteUnreferencedObject(checksum string, fn func() error) (bool, error)
+	FindObjectKeys(result *[]string) error
+	AssignPet(petId string, ids []string) error
+	UnassignPet(ids []string) error
+	MovePet(fromPetId string, toPetId string, ids []string) error
+	Reorder(petId string, ids []string) error
+	SetCover(petId string, id string) error
 	Create(in *model.Image) error
 	Update(id string, in *model.Image) error
+	ConfirmUpload(id string, in *model.Image) error
 	Delete(id string) error
+	DeleteUnassigned(id string, before time.Time) error
+	DeleteExpiredUpload(id string, before time.Time) error
+	Restore(id string, result *model.Image) error
+	FindDeleted(before time.Time, limit int, result *[]*model.Image) error
+	Purge(id string, before time.Time) error
+	CreateFailedCompensations(in []*model.FailedCompensation) error
+	FindFailedCompensations(limit int, result *[]*model.FailedCompensation) error
+	UpdateFailedCompensation(id string, in *model.FailedCompensation) error
+	DeleteFailedCompensation(id string) error
 }
@@ -0,0 +1,800 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: johnjud/file/image/v1/image.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ImageService_Upload_FullMethodName            = "/johnjud.file.image.v1.ImageService/Upload"
+	ImageService_UploadStream_FullMethodName      = "/johnjud.file.image.v1.ImageService/UploadStream"
+	ImageService_FindByPetId_FullMethodName       = "/johnjud.file.image.v1.ImageService/FindByPetId"
+	ImageService_FindOne_FullMethodName           = "/johnjud.file.image.v1.ImageService/FindOne"
+	ImageService_FindByIds_FullMethodName         = "/johnjud.file.image.v1.ImageService/FindByIds"
+	ImageService_List_FullMethodName              = "/johnjud.file.image.v1.ImageService/List"
+	ImageService_AssignPet_FullMethodName         = "/johnjud.file.image.v1.ImageService/AssignPet"
+	ImageService_UnassignPet_FullMethodName       = "/johnjud.file.image.v1.ImageService/UnassignPet"
+	ImageService_MoveImages_FullMethodName        = "/johnjud.file.image.v1.ImageService/MoveImages"
+	ImageService_Reorder_FullMethodName           = "/johnjud.file.image.v1.ImageService/Reorder"
+	ImageService_SetCover_FullMethodName          = "/johnjud.file.image.v1.ImageService/SetCover"
+	ImageService_Delete_FullMethodName            = "/johnjud.file.image.v1.ImageService/Delete"
+	ImageService_Restore_FullMethodName           = "/johnjud.file.image.v1.ImageService/Restore"
+	ImageService_Download_FullMethodName          = "/johnjud.file.image.v1.ImageService/Download"
+	ImageService_CreateUploadUrl_FullMethodName   = "/johnjud.file.image.v1.ImageService/CreateUploadUrl"
+	ImageService_ConfirmUpload_FullMethodName     = "/johnjud.file.image.v1.ImageService/ConfirmUpload"
+	ImageService_CreateDownloadUrl_FullMethodName = "/johnjud.file.image.v1.ImageService/CreateDownloadUrl"
+	ImageService_FindSimilar_FullMethodName       = "/johnjud.file.image.v1.ImageService/FindSimilar"
+)
+
+// ImageServiceClient is the client API for ImageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ImageServiceClient interface {
+	Upload(ctx context.Context, in *UploadImageRequest, opts ...grpc.CallOption) (*UploadImageResponse, error)
+	UploadStream(ctx context.Context, opts ...grpc.CallOption) (ImageService_UploadStreamClient, error)
+	FindByPetId(ctx context.Context, in *FindImageByPetIdRequest, opts ...grpc.CallOption) (*FindImageByPetIdResponse, error)
+	FindOne(ctx context.Context, in *FindOneImageRequest, opts ...grpc.CallOption) (*FindOneImageResponse, error)
+	FindByIds(ctx context.Context, in *FindImagesByIdsRequest, opts ...grpc.CallOption) (*FindImagesByIdsResponse, error)
+	List(ctx context.Context, in *ListImagesRequest, opts ...grpc.CallOption) (*ListImagesResponse, error)
+	AssignPet(ctx context.Context, in *AssignPetRequest, opts ...grpc.CallOption) (*AssignPetResponse, error)
+	UnassignPet(ctx context.Context, in *UnassignPetRequest, opts ...grpc.CallOption) (*UnassignPetResponse, error)
+	MoveImages(ctx context.Context, in *MoveImagesRequest, opts ...grpc.CallOption) (*MoveImagesResponse, error)
+	Reorder(ctx context.Context, in *ReorderImagesRequest, opts ...grpc.CallOption) (*ReorderImagesResponse, error)
+	SetCover(ctx context.Context, in *SetCoverImageRequest, opts ...grpc.CallOption) (*SetCoverImageResponse, error)
+	Delete(ctx context.Context, in *DeleteImageRequest, opts ...grpc.CallOption) (*DeleteImageResponse, error)
+	Restore(ctx context.Context, in *RestoreImageRequest, opts ...grpc.CallOption) (*RestoreImageResponse, error)
+	Download(ctx context.Context, in *DownloadImageRequest, opts ...grpc.CallOption) (ImageService_DownloadClient, error)
+	CreateUploadUrl(ctx context.Context, in *CreateUploadUrlRequest, opts ...grpc.CallOption) (*CreateUploadUrlResponse, error)
+	ConfirmUpload(ctx context.Context, in *ConfirmUploadRequest, opts ...grpc.CallOption) (*ConfirmUploadResponse, error)
+	CreateDownloadUrl(ctx context.Context, in *CreateDownloadUrlRequest, opts ...grpc.CallOption) (*CreateDownloadUrlResponse, error)
+	FindSimilar(ctx context.Context, in *FindSimilarImagesRequest, opts ...grpc.CallOption) (*FindSimilarImagesResponse, error)
+}
+
+type imageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewImageServiceClient(cc grpc.ClientConnInterface) ImageServiceClient {
+	return &imageServiceClient{cc}
+}
+
+func (c *imageServiceClient) Upload(ctx context.Context, in *UploadImageRequest, opts ...grpc.CallOption) (*UploadImageResponse, error) {
+	out := new(UploadImageResponse)
+	err := c.cc.Invoke(ctx, ImageService_Upload_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) UploadStream(ctx context.Context, opts ...grpc.CallOption) (ImageService_UploadStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ImageService_ServiceDesc.Streams[0], ImageService_UploadStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &imageServiceUploadStreamClient{stream}
+	return x, nil
+}
+
+type ImageService_UploadStreamClient interface {
+	Send(*UploadImageStreamRequest) error
+	CloseAndRecv() (*UploadImageResponse, error)
+	grpc.ClientStream
+}
+
+type imageServiceUploadStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *imageServiceUploadStreamClient) Send(m *UploadImageStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *imageServiceUploadStreamClient) CloseAndRecv() (*UploadImageResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UploadImageResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *imageServiceClient) FindByPetId(ctx context.Context, in *FindImageByPetIdRequest, opts ...grpc.CallOption) (*FindImageByPetIdResponse, error) {
+	out := new(FindImageByPetIdResponse)
+	err := c.cc.Invoke(ctx, ImageService_FindByPetId_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) FindOne(ctx context.Context, in *FindOneImageRequest, opts ...grpc.CallOption) (*FindOneImageResponse, error) {
+	out := new(FindOneImageResponse)
+	err := c.cc.Invoke(ctx, ImageService_FindOne_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) FindByIds(ctx context.Context, in *FindImagesByIdsRequest, opts ...grpc.CallOption) (*FindImagesByIdsResponse, error) {
+	out := new(FindImagesByIdsResponse)
+	err := c.cc.Invoke(ctx, ImageService_FindByIds_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) List(ctx context.Context, in *ListImagesRequest, opts ...grpc.CallOption) (*ListImagesResponse, error) {
+	out := new(ListImagesResponse)
+	err := c.cc.Invoke(ctx, ImageService_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) AssignPet(ctx context.Context, in *AssignPetRequest, opts ...grpc.CallOption) (*AssignPetResponse, error) {
+	out := new(AssignPetResponse)
+	err := c.cc.Invoke(ctx, ImageService_AssignPet_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) UnassignPet(ctx context.Context, in *UnassignPetRequest, opts ...grpc.CallOption) (*UnassignPetResponse, error) {
+	out := new(UnassignPetResponse)
+	err := c.cc.Invoke(ctx, ImageService_UnassignPet_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) MoveImages(ctx context.Context, in *MoveImagesRequest, opts ...grpc.CallOption) (*MoveImagesResponse, error) {
+	out := new(MoveImagesResponse)
+	err := c.cc.Invoke(ctx, ImageService_MoveImages_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) Reorder(ctx context.Context, in *ReorderImagesRequest, opts ...grpc.CallOption) (*ReorderImagesResponse, error) {
+	out := new(ReorderImagesResponse)
+	err := c.cc.Invoke(ctx, ImageService_Reorder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) SetCover(ctx context.Context, in *SetCoverImageRequest, opts ...grpc.CallOption) (*SetCoverImageResponse, error) {
+	out := new(SetCoverImageResponse)
+	err := c.cc.Invoke(ctx, ImageService_SetCover_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) Delete(ctx context.Context, in *DeleteImageRequest, opts ...grpc.CallOption) (*DeleteImageResponse, error) {
+	out := new(DeleteImageResponse)
+	err := c.cc.Invoke(ctx, ImageService_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) Restore(ctx context.Context, in *RestoreImageRequest, opts ...grpc.CallOption) (*RestoreImageResponse, error) {
+	out := new(RestoreImageResponse)
+	err := c.cc.Invoke(ctx, ImageService_Restore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) Download(ctx context.Context, in *DownloadImageRequest, opts ...grpc.CallOption) (ImageService_DownloadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ImageService_ServiceDesc.Streams[1], ImageService_Download_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &imageServiceDownloadClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ImageService_DownloadClient interface {
+	Recv() (*DownloadImageResponse, error)
+	grpc.ClientStream
+}
+
+type imageServiceDownloadClient struct {
+	grpc.ClientStream
+}
+
+func (x *imageServiceDownloadClient) Recv() (*DownloadImageResponse, error) {
+	m := new(DownloadImageResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *imageServiceClient) CreateUploadUrl(ctx context.Context, in *CreateUploadUrlRequest, opts ...grpc.CallOption) (*CreateUploadUrlResponse, error) {
+	out := new(CreateUploadUrlResponse)
+	err := c.cc.Invoke(ctx, ImageService_CreateUploadUrl_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) ConfirmUpload(ctx context.Context, in *ConfirmUploadRequest, opts ...grpc.CallOption) (*ConfirmUploadResponse, error) {
+	out := new(ConfirmUploadResponse)
+	err := c.cc.Invoke(ctx, ImageService_ConfirmUpload_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) CreateDownloadUrl(ctx context.Context, in *CreateDownloadUrlRequest, opts ...grpc.CallOption) (*CreateDownloadUrlResponse, error) {
+	out := new(CreateDownloadUrlResponse)
+	err := c.cc.Invoke(ctx, ImageService_CreateDownloadUrl_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) FindSimilar(ctx context.Context, in *FindSimilarImagesRequest, opts ...grpc.CallOption) (*FindSimilarImagesResponse, error) {
+	out := new(FindSimilarImagesResponse)
+	err := c.cc.Invoke(ctx, ImageService_FindSimilar_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ImageServiceServer is the server API for ImageService service.
+// All implementations must embed UnimplementedImageServiceServer
+// for forward compatibility
+type ImageServiceServer interface {
+	Upload(context.Context, *UploadImageRequest) (*UploadImageResponse, error)
+	UploadStream(ImageService_UploadStreamServer) error
+	FindByPetId(context.Context, *FindImageByPetIdRequest) (*FindImageByPetIdResponse, error)
+	FindOne(context.Context, *FindOneImageRequest) (*FindOneImageResponse, error)
+	FindByIds(context.Context, *FindImagesByIdsRequest) (*FindImagesByIdsResponse, error)
+	List(context.Context, *ListImagesRequest) (*ListImagesResponse, error)
+	AssignPet(context.Context, *AssignPetRequest) (*AssignPetResponse, error)
+	UnassignPet(context.Context, *UnassignPetRequest) (*UnassignPetResponse, error)
+	MoveImages(context.Context, *MoveImagesRequest) (*MoveImagesResponse, error)
+	Reorder(context.Context, *ReorderImagesRequest) (*ReorderImagesResponse, error)
+	SetCover(context.Context, *SetCoverImageRequest) (*SetCoverImageResponse, error)
+	Delete(context.Context, *DeleteImageRequest) (*DeleteImageResponse, error)
+	Restore(context.Context, *RestoreImageRequest) (*RestoreImageResponse, error)
+	Download(*DownloadImageRequest, ImageService_DownloadServer) error
+	CreateUploadUrl(context.Context, *CreateUploadUrlRequest) (*CreateUploadUrlResponse, error)
+	ConfirmUpload(context.Context, *ConfirmUploadRequest) (*ConfirmUploadResponse, error)
+	CreateDownloadUrl(context.Context, *CreateDownloadUrlRequest) (*CreateDownloadUrlResponse, error)
+	FindSimilar(context.Context, *FindSimilarImagesRequest) (*FindSimilarImagesResponse, error)
+	mustEmbedUnimplementedImageServiceServer()
+}
+
+// UnimplementedImageServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedImageServiceServer struct {
+}
+
+func (UnimplementedImageServiceServer) Upload(context.Context, *UploadImageRequest) (*UploadImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Upload not implemented")
+}
+func (UnimplementedImageServiceServer) UploadStream(ImageService_UploadStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadStream not implemented")
+}
+func (UnimplementedImageServiceServer) FindByPetId(context.Context, *FindImageByPetIdRequest) (*FindImageByPetIdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindByPetId not implemented")
+}
+func (UnimplementedImageServiceServer) FindOne(context.Context, *FindOneImageRequest) (*FindOneImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindOne not implemented")
+}
+func (UnimplementedImageServiceServer) FindByIds(context.Context, *FindImagesByIdsRequest) (*FindImagesByIdsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindByIds not implemented")
+}
+func (UnimplementedImageServiceServer) List(context.Context, *ListImagesRequest) (*ListImagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedImageServiceServer) AssignPet(context.Context, *AssignPetRequest) (*AssignPetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignPet not implemented")
+}
+func (UnimplementedImageServiceServer) UnassignPet(context.Context, *UnassignPetRequest) (*UnassignPetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnassignPet not implemented")
+}
+func (UnimplementedImageServiceServer) MoveImages(context.Context, *MoveImagesRequest) (*MoveImagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveImages not implemented")
+}
+func (UnimplementedImageServiceServer) Reorder(context.Context, *ReorderImagesRequest) (*ReorderImagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reorder not implemented")
+}
+func (UnimplementedImageServiceServer) SetCover(context.Context, *SetCoverImageRequest) (*SetCoverImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCover not implemented")
+}
+func (UnimplementedImageServiceServer) Delete(context.Context, *DeleteImageRequest) (*DeleteImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedImageServiceServer) Restore(context.Context, *RestoreImageRequest) (*RestoreImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedImageServiceServer) Download(*DownloadImageRequest, ImageService_DownloadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Download not implemented")
+}
+func (UnimplementedImageServiceServer) CreateUploadUrl(context.Context, *CreateUploadUrlRequest) (*CreateUploadUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUploadUrl not implemented")
+}
+func (UnimplementedImageServiceServer) ConfirmUpload(context.Context, *ConfirmUploadRequest) (*ConfirmUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmUpload not implemented")
+}
+func (UnimplementedImageServiceServer) CreateDownloadUrl(context.Context, *CreateDownloadUrlRequest) (*CreateDownloadUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDownloadUrl not implemented")
+}
+func (UnimplementedImageServiceServer) FindSimilar(context.Context, *FindSimilarImagesRequest) (*FindSimilarImagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindSimilar not implemented")
+}
+func (UnimplementedImageServiceServer) mustEmbedUnimplementedImageServiceServer() {}
+
+// UnsafeImageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ImageServiceServer will
+// result in compilation errors.
+type UnsafeImageServiceServer interface {
+	mustEmbedUnimplementedImageServiceServer()
+}
+
+func RegisterImageServiceServer(s grpc.ServiceRegistrar, srv ImageServiceServer) {
+	s.RegisterService(&ImageService_ServiceDesc, srv)
+}
+
+func _ImageService_Upload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).Upload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_Upload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).Upload(ctx, req.(*UploadImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_UploadStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ImageServiceServer).UploadStream(&imageServiceUploadStreamServer{stream})
+}
+
+type ImageService_UploadStreamServer interface {
+	SendAndClose(*UploadImageResponse) error
+	Recv() (*UploadImageStreamRequest, error)
+	grpc.ServerStream
+}
+
+type imageServiceUploadStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *imageServiceUploadStreamServer) SendAndClose(m *UploadImageResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *imageServiceUploadStreamServer) Recv() (*UploadImageStreamRequest, error) {
+	m := new(UploadImageStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ImageService_FindByPetId_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindImageByPetIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).FindByPetId(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_FindByPetId_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).FindByPetId(ctx, req.(*FindImageByPetIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_FindOne_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindOneImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).FindOne(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_FindOne_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).FindOne(ctx, req.(*FindOneImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_FindByIds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindImagesByIdsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).FindByIds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_FindByIds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).FindByIds(ctx, req.(*FindImagesByIdsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).List(ctx, req.(*ListImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_AssignPet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignPetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).AssignPet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_AssignPet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).AssignPet(ctx, req.(*AssignPetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_UnassignPet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnassignPetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).UnassignPet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_UnassignPet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).UnassignPet(ctx, req.(*UnassignPetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_MoveImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).MoveImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_MoveImages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).MoveImages(ctx, req.(*MoveImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_Reorder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).Reorder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_Reorder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).Reorder(ctx, req.(*ReorderImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_SetCover_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCoverImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).SetCover(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_SetCover_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).SetCover(ctx, req.(*SetCoverImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).Delete(ctx, req.(*DeleteImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).Restore(ctx, req.(*RestoreImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_Download_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadImageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ImageServiceServer).Download(m, &imageServiceDownloadServer{stream})
+}
+
+type ImageService_DownloadServer interface {
+	Send(*DownloadImageResponse) error
+	grpc.ServerStream
+}
+
+type imageServiceDownloadServer struct {
+	grpc.ServerStream
+}
+
+func (x *imageServiceDownloadServer) Send(m *DownloadImageResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ImageService_CreateUploadUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUploadUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).CreateUploadUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_CreateUploadUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).CreateUploadUrl(ctx, req.(*CreateUploadUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_ConfirmUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).ConfirmUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_ConfirmUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).ConfirmUpload(ctx, req.(*ConfirmUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_CreateDownloadUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDownloadUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).CreateDownloadUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_CreateDownloadUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).CreateDownloadUrl(ctx, req.(*CreateDownloadUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_FindSimilar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindSimilarImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).FindSimilar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_FindSimilar_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).FindSimilar(ctx, req.(*FindSimilarImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ImageService_ServiceDesc is the grpc.ServiceDesc for ImageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ImageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "johnjud.file.image.v1.ImageService",
+	HandlerType: (*ImageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Upload",
+			Handler:    _ImageService_Upload_Handler,
+		},
+		{
+			MethodName: "FindByPetId",
+			Handler:    _ImageService_FindByPetId_Handler,
+		},
+		{
+			MethodName: "FindOne",
+			Handler:    _ImageService_FindOne_Handler,
+		},
+		{
+			MethodName: "FindByIds",
+			Handler:    _ImageService_FindByIds_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _ImageService_List_Handler,
+		},
+		{
+			MethodName: "AssignPet",
+			Handler:    _ImageService_AssignPet_Handler,
+		},
+		{
+			MethodName: "UnassignPet",
+			Handler:    _ImageService_UnassignPet_Handler,
+		},
+		{
+			MethodName: "MoveImages",
+			Handler:    _ImageService_MoveImages_Handler,
+		},
+		{
+			MethodName: "Reorder",
+			Handler:    _ImageService_Reorder_Handler,
+		},
+		{
+			MethodName: "SetCover",
+			Handler:    _ImageService_SetCover_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _ImageService_Delete_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _ImageService_Restore_Handler,
+		},
+		{
+			MethodName: "CreateUploadUrl",
+			Handler:    _ImageService_CreateUploadUrl_Handler,
+		},
+		{
+			MethodName: "ConfirmUpload",
+			Handler:    _ImageService_ConfirmUpload_Handler,
+		},
+		{
+			MethodName: "CreateDownloadUrl",
+			Handler:    _ImageService_CreateDownloadUrl_Handler,
+		},
+		{
+			MethodName: "FindSimilar",
+			Handler:    _ImageService_FindSimilar_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadStream",
+			Handler:       _ImageService_UploadStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Download",
+			Handler:       _ImageService_Download_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "johnjud/file/image/v1/image.proto",
+}